@@ -14,6 +14,7 @@ replace github.com/fullpipe/icu-mf => ../..
 require (
 	github.com/alecthomas/participle/v2 v2.1.4 // indirect
 	github.com/kr/text v0.2.0 // indirect
+	github.com/pelletier/go-toml/v2 v2.3.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rogpeppe/go-internal v1.13.1 // indirect
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
@@ -1,17 +1,21 @@
 package tr
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/fullpipe/icu-mf/mf"
 )
 
-// LangMiddleware bypasses user language to request context
+// LangMiddleware puts translator for user language to request context
 func LangMiddleware(sessionManager *scs.SessionManager, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		lang := sessionManager.GetString(r.Context(), "lang")
-		r = r.WithContext(context.WithValue(r.Context(), langKey, lang))
+		if lang == "" {
+			lang = "en"
+		}
+
+		r = r.WithContext(mf.WithTranslator(r.Context(), bundle.Translator(lang)))
 
 		next.ServeHTTP(w, r)
 	})
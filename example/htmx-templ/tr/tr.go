@@ -9,12 +9,9 @@ import (
 	"golang.org/x/text/language"
 )
 
-type contextKey string
-
 //go:embed messages/messages.*.yaml
 var messagesDir embed.FS
 var bundle mf.Bundle
-var langKey contextKey = "lang"
 
 func init() {
 	var err error
@@ -37,12 +34,7 @@ func init() {
 	}
 }
 
-// Tr translates message
+// Tr translates message with translator from LangMiddleware
 func Tr(ctx context.Context, path string, args ...mf.TranslationArg) string {
-	lang := ctx.Value(langKey).(string)
-	if lang == "" {
-		lang = "en"
-	}
-
-	return bundle.Translator(lang).Trans(path, args...)
+	return mf.T(ctx, path, args...)
 }
@@ -6,6 +6,7 @@ toolchain go1.24.1
 
 require (
 	github.com/alecthomas/participle/v2 v2.1.4
+	github.com/pelletier/go-toml/v2 v2.3.1
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394
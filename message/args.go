@@ -0,0 +1,177 @@
+package message
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ArgKind is how a message uses an argument.
+type ArgKind int
+
+const (
+	// ArgString is a plain {arg}, any value is printed with fmt.
+	ArgString ArgKind = iota
+	// ArgNumber is used by number, plural and selectordinal.
+	ArgNumber
+	// ArgTime is used by date, time and datetime.
+	ArgTime
+	// ArgSelectKey is used by select.
+	ArgSelectKey
+)
+
+func (k ArgKind) String() string {
+	switch k {
+	case ArgString:
+		return "string"
+	case ArgNumber:
+		return "number"
+	case ArgTime:
+		return "time"
+	case ArgSelectKey:
+		return "select-key"
+	default:
+		return fmt.Sprintf("ArgKind(%d)", int(k))
+	}
+}
+
+// PathStep is a select or plural case an argument is nested in.
+type PathStep struct {
+	Arg  string
+	Case string
+}
+
+// ArgInfo describes a single use of an argument in a message.
+type ArgInfo struct {
+	Name string
+	Kind ArgKind
+	// Cases are select keys or plural cases, like "one" or "=0".
+	Cases []string
+	// Ordinal is true for selectordinal.
+	Ordinal bool
+	// Offset of plural.
+	Offset int
+	// Path of cases the argument is nested in, outermost first.
+	Path []PathStep
+}
+
+// ArgLister is implemented by evaluables that use arguments.
+type ArgLister interface {
+	Args() []ArgInfo
+}
+
+// Args returns all uses of arguments in e, in order of appearance.
+// Evaluables that do not implement ArgLister have no arguments.
+func Args(e Evalable) []ArgInfo {
+	if l, ok := e.(ArgLister); ok {
+		return l.Args()
+	}
+
+	return nil
+}
+
+func (m *Message) Args() []ArgInfo {
+	var args []ArgInfo
+	for _, f := range m.fragments {
+		args = append(args, Args(f)...)
+	}
+
+	return args
+}
+
+func (Content) Args() []ArgInfo {
+	return nil
+}
+
+func (pa PlainArg) Args() []ArgInfo {
+	// # is the number of enclosing plural, not an argument
+	if pa == "#" {
+		return nil
+	}
+
+	return []ArgInfo{{Name: string(pa), Kind: ArgString}}
+}
+
+func (n Number) Args() []ArgInfo {
+	return []ArgInfo{{Name: n.ArgName, Kind: ArgNumber}}
+}
+
+func (dt Datetime) Args() []ArgInfo {
+	return []ArgInfo{{Name: dt.argName, Kind: ArgTime}}
+}
+
+func (s *Select) Args() []ArgInfo {
+	keys := slices.Sorted(maps.Keys(s.Cases))
+	args := []ArgInfo{{Name: s.ArgName, Kind: ArgSelectKey, Cases: keys}}
+
+	for _, key := range keys {
+		args = append(args, nested(s.ArgName, key, s.Cases[key])...)
+	}
+
+	if s.star != nil {
+		args = append(args, nested(s.ArgName, "*", s.star)...)
+	}
+
+	return args
+}
+
+func (p *Plural) Args() []ArgInfo {
+	eqs := slices.Sorted(maps.Keys(p.EqCases))
+
+	cases := make([]string, 0, len(eqs)+len(p.Cases))
+	for _, eq := range eqs {
+		cases = append(cases, "="+strconv.FormatUint(eq, 10))
+	}
+
+	for _, name := range formOrder {
+		if _, ok := p.Cases[strToFormMap[name]]; ok && !slices.Contains(p.starCases, strToFormMap[name]) {
+			cases = append(cases, name)
+		}
+	}
+
+	args := []ArgInfo{{Name: p.ArgName, Kind: ArgNumber, Cases: cases, Ordinal: p.Ordinal, Offset: p.Offset}}
+
+	for _, eq := range eqs {
+		args = append(args, nested(p.ArgName, "="+strconv.FormatUint(eq, 10), p.EqCases[eq])...)
+	}
+
+	for _, name := range formOrder {
+		if c, ok := p.Cases[strToFormMap[name]]; ok && !slices.Contains(p.starCases, strToFormMap[name]) {
+			args = append(args, nested(p.ArgName, name, c)...)
+		}
+	}
+
+	return args
+}
+
+func (c *Choice) Args() []ArgInfo {
+	// choice has no plural cases, it is a plain number for Check
+	args := []ArgInfo{{Name: c.ArgName, Kind: ArgNumber}}
+	for _, cc := range c.Cases {
+		args = append(args, nested(c.ArgName, cc.Key, cc.Eval)...)
+	}
+
+	return args
+}
+
+// nested returns args of a case with the case prepended to their paths.
+func nested(arg, key string, e Evalable) []ArgInfo {
+	args := Args(e)
+	for i := range args {
+		args[i].Path = append([]PathStep{{Arg: arg, Case: key}}, args[i].Path...)
+	}
+
+	return args
+}
+
+var (
+	_ ArgLister = (*Message)(nil)
+	_ ArgLister = Content("")
+	_ ArgLister = PlainArg("")
+	_ ArgLister = (*Number)(nil)
+	_ ArgLister = (*Datetime)(nil)
+	_ ArgLister = (*Select)(nil)
+	_ ArgLister = (*Plural)(nil)
+	_ ArgLister = (*Choice)(nil)
+)
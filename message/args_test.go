@@ -0,0 +1,73 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []ArgInfo
+	}{
+		{"text", "Hello!", nil},
+		{"plain", "Hello {name}!", []ArgInfo{{Name: "name", Kind: ArgString}}},
+		{
+			"functions",
+			"{n, number} {d, date, short} {t, time}",
+			[]ArgInfo{
+				{Name: "n", Kind: ArgNumber},
+				{Name: "d", Kind: ArgTime},
+				{Name: "t", Kind: ArgTime},
+			},
+		},
+		{
+			"nested",
+			"{gender, select, male {He has {count, plural, =0 {no} one {# apple} other {{count, number} apples}}} other {{name}}}",
+			[]ArgInfo{
+				{Name: "gender", Kind: ArgSelectKey, Cases: []string{"male", "other"}},
+				{
+					Name: "count", Kind: ArgNumber, Cases: []string{"=0", "one", "other"},
+					Path: []PathStep{{"gender", "male"}},
+				},
+				{
+					Name: "count", Kind: ArgNumber,
+					Path: []PathStep{{"gender", "male"}, {"count", "other"}},
+				},
+				{Name: "name", Kind: ArgString, Path: []PathStep{{"gender", "other"}}},
+			},
+		},
+		{
+			"ordinal",
+			"{pos, selectordinal, one {#st} other {#th}}",
+			[]ArgInfo{{Name: "pos", Kind: ArgNumber, Cases: []string{"one", "other"}, Ordinal: true}},
+		},
+		{
+			"several selectors",
+			"{count, gender, plural select, =0 male {none} other other {#}}",
+			[]ArgInfo{
+				{Name: "count", Kind: ArgNumber, Cases: []string{"=0", "other"}},
+				// * variants are tried after =0 male
+				{Name: "gender", Kind: ArgSelectKey, Cases: []string{"male"}, Path: []PathStep{{"count", "=0"}}},
+				{Name: "gender", Kind: ArgSelectKey, Cases: []string{"other"}, Path: []PathStep{{"count", "=0"}}},
+				{Name: "gender", Kind: ArgSelectKey, Cases: []string{"other"}, Path: []PathStep{{"count", "other"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parse.Parse(tt.in)
+			require.NoError(t, err)
+
+			eval, err := Build(*msg, language.English)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, Args(eval))
+		})
+	}
+}
@@ -4,14 +4,39 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/fullpipe/icu-mf/parse"
 	"golang.org/x/text/language"
 )
 
-func Build(in parse.Message, lang language.Tag) (Evalable, error) {
+// BuildOption configures how a message is built.
+type BuildOption func(o *buildOptions)
+
+type buildOptions struct {
+	strictSelect bool
+}
+
+// WithStrictSelect makes select expressions fail on a missing argument, or an argument
+// of unsupported type, instead of silently falling back to the 'other' case.
+func WithStrictSelect() BuildOption {
+	return func(o *buildOptions) {
+		o.strictSelect = true
+	}
+}
+
+func Build(in parse.Message, lang language.Tag, opts ...BuildOption) (Evalable, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return build(in, lang, o)
+}
+
+func build(in parse.Message, lang language.Tag, o buildOptions) (Evalable, error) {
 	if len(in.Fragments) == 1 {
-		return buildFragment(*in.Fragments[0], lang)
+		return buildFragment(*in.Fragments[0], lang, o)
 	}
 
 	root := &Message{
@@ -19,7 +44,7 @@ func Build(in parse.Message, lang language.Tag) (Evalable, error) {
 	}
 
 	for _, f := range in.Fragments {
-		eval, err := buildFragment(*f, lang)
+		eval, err := buildFragment(*f, lang, o)
 		if err != nil {
 			return nil, err
 		}
@@ -29,7 +54,7 @@ func Build(in parse.Message, lang language.Tag) (Evalable, error) {
 	return root, nil
 }
 
-func buildFragment(f parse.Fragment, lang language.Tag) (Evalable, error) {
+func buildFragment(f parse.Fragment, lang language.Tag, o buildOptions) (Evalable, error) {
 	switch {
 	case len(f.Escaped) > 0:
 		return Content(f.Escaped[1:]), nil
@@ -42,7 +67,7 @@ func buildFragment(f parse.Fragment, lang language.Tag) (Evalable, error) {
 	case f.Func != nil:
 		return buildFunc(f.Func, lang)
 	case f.Expr != nil:
-		return buildExpr(f.Expr, lang)
+		return buildExpr(f.Expr, lang, o)
 	default:
 		return nil, errors.New("empty fragment")
 	}
@@ -55,22 +80,28 @@ func buildFunc(f *parse.Func, lang language.Tag) (Evalable, error) {
 	case "date", "time", "datetime":
 		return buildDatetime(f, lang)
 	default:
-		return nil, fmt.Errorf("unsupported function: %s", f.Func)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, f.Func)
 	}
 }
 
-func buildExpr(e *parse.Expr, lang language.Tag) (Evalable, error) {
+func buildExpr(e *parse.Expr, lang language.Tag, o buildOptions) (Evalable, error) {
+	if len(e.Selectors) > 0 {
+		return buildMultiSelect(e, lang, o)
+	}
+
 	switch e.Func {
 	case "select":
-		return buildSelect(e, lang)
+		return buildSelect(e, lang, o)
 	case "plural", "selectordinal":
-		return buildPlural(e, lang)
+		return buildPlural(e, lang, o)
+	case "choice":
+		return buildChoice(e, lang, o)
 	default:
-		return nil, fmt.Errorf("unsupported expression: %s", e.Func)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, e.Func)
 	}
 }
 
-func buildSelect(e *parse.Expr, lang language.Tag) (Evalable, error) {
+func buildSelect(e *parse.Expr, lang language.Tag, o buildOptions) (Evalable, error) {
 	if e == nil || e.Name == "" || e.Func != "select" {
 		return nil, errors.New("invalid select expression")
 	}
@@ -82,6 +113,7 @@ func buildSelect(e *parse.Expr, lang language.Tag) (Evalable, error) {
 	eval := &Select{
 		ArgName: e.Name,
 		Cases:   make(map[string]Evalable, len(e.Cases)),
+		Strict:  o.strictSelect,
 	}
 
 	hasDefaultCase := false
@@ -90,7 +122,7 @@ func buildSelect(e *parse.Expr, lang language.Tag) (Evalable, error) {
 			hasDefaultCase = true
 		}
 
-		caseEval, err := Build(*c.Message, lang)
+		caseEval, err := build(*c.Message, lang, o)
 		if err != nil {
 			return nil, err
 		}
@@ -105,7 +137,38 @@ func buildSelect(e *parse.Expr, lang language.Tag) (Evalable, error) {
 	return eval, nil
 }
 
-func buildPlural(e *parse.Expr, lang language.Tag) (Evalable, error) {
+func buildChoice(e *parse.Expr, lang language.Tag, o buildOptions) (Evalable, error) {
+	if len(e.Cases) == 0 {
+		return nil, fmt.Errorf("no cases for {%s, choice ...}", e.Name)
+	}
+
+	eval := &Choice{ArgName: e.Name, Cases: make([]ChoiceCase, 0, len(e.Cases))}
+	for i, c := range e.Cases {
+		limit, strict, err := parse.ChoiceLimit(c.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		// a limit could repeat only as # and then <
+		if i > 0 {
+			prev := eval.Cases[i-1]
+			if limit < prev.Limit || limit == prev.Limit && (prev.Strict || !strict) {
+				return nil, fmt.Errorf("choice limits are not ascending: %s after %s", c.Name, prev.Key)
+			}
+		}
+
+		caseEval, err := build(*c.Message, lang, o)
+		if err != nil {
+			return nil, err
+		}
+
+		eval.Cases = append(eval.Cases, ChoiceCase{Key: c.Name, Limit: limit, Strict: strict, Eval: caseEval})
+	}
+
+	return eval, nil
+}
+
+func buildPlural(e *parse.Expr, lang language.Tag, o buildOptions) (Evalable, error) {
 	if e == nil || e.Name == "" || (e.Func != "plural" && e.Func != "selectordinal") {
 		return nil, errors.New("invalid plural expression")
 	}
@@ -134,7 +197,7 @@ func buildPlural(e *parse.Expr, lang language.Tag) (Evalable, error) {
 			hasDefaultCase = true
 		}
 
-		caseEval, err := Build(*c.Message, lang)
+		caseEval, err := build(*c.Message, lang, o)
 		if err != nil {
 			return nil, err
 		}
@@ -159,10 +222,77 @@ func buildPlural(e *parse.Expr, lang language.Tag) (Evalable, error) {
 	return eval, nil
 }
 
+// buildMultiSelect builds {gender, count, select plural, ...} expression,
+// where 'other' key matches any value, like in select and plural.
+func buildMultiSelect(e *parse.Expr, lang language.Tag, o buildOptions) (Evalable, error) {
+	if e.Offset < 0 {
+		return nil, errors.New("offset should be positive")
+	}
+
+	args := make([]*parse.Selector, 0, len(e.Selectors)+1)
+	args = append(args, &parse.Selector{Name: e.Name, Func: e.Func})
+	args = append(args, e.Selectors...)
+
+	sels := make([]matchSelector, 0, len(args))
+	for _, a := range args {
+		sel := matchSelector{ArgName: a.Name}
+		switch a.Func {
+		case "select":
+			sel.Kind, sel.DefaultOther = selectString, true
+		case "plural":
+			sel.Kind, sel.Offset = selectPlural, e.Offset
+		case "selectordinal":
+			sel.Kind, sel.Offset = selectOrdinal, e.Offset
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, a.Func)
+		}
+
+		sels = append(sels, sel)
+	}
+
+	seen := map[string]bool{}
+	hasDefaultCase := false
+	variants := make([]matchVariant, 0, len(e.Cases))
+	for _, c := range e.Cases {
+		names := append([]string{c.Name}, c.Keys...)
+		if len(names) != len(sels) {
+			return nil, fmt.Errorf("case %s has %d keys, expected %d", strings.Join(names, " "), len(names), len(sels))
+		}
+
+		id := strings.Join(names, " ")
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate case %s", id)
+		}
+
+		seen[id] = true
+
+		caseEval, err := build(*c.Message, lang, o)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]matchKey, 0, len(names))
+		isDefault := true
+		for _, name := range names {
+			keys = append(keys, matchKey{Value: name, Star: name == DefaultCase})
+			isDefault = isDefault && name == DefaultCase
+		}
+
+		hasDefaultCase = hasDefaultCase || isDefault
+		variants = append(variants, matchVariant{Keys: keys, Eval: caseEval})
+	}
+
+	if !hasDefaultCase {
+		return nil, fmt.Errorf("no '%s' case", strings.TrimSpace(strings.Repeat(DefaultCase+" ", len(sels))))
+	}
+
+	return buildMatch(sels, variants, lang, o)
+}
+
 func buildNumber(f *parse.Func, lang language.Tag) (Evalable, error) {
 	format, ok := strToNumberFormatMap[f.Param]
 	if !ok {
-		return nil, fmt.Errorf("number format %s not supported", f.Param)
+		return nil, fmt.Errorf("%w: number format %s", ErrUnsupportedFunction, f.Param)
 	}
 
 	return NewNumber(f.ArgName, format, lang), nil
@@ -171,7 +301,7 @@ func buildNumber(f *parse.Func, lang language.Tag) (Evalable, error) {
 func buildDatetime(f *parse.Func, lang language.Tag) (Evalable, error) {
 	format, ok := strToDatetimeFormatMap[f.Param]
 	if !ok {
-		return nil, fmt.Errorf("date format %s not supported", f.Param)
+		return nil, fmt.Errorf("%w: date format %s", ErrUnsupportedFunction, f.Param)
 	}
 
 	switch f.Func {
@@ -182,6 +312,6 @@ func buildDatetime(f *parse.Func, lang language.Tag) (Evalable, error) {
 	case "datetime":
 		return NewDatetime(f.ArgName, format, lang), nil
 	default:
-		return nil, fmt.Errorf("unsupported datetime function: %s", f.Func)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, f.Func)
 	}
 }
@@ -0,0 +1,285 @@
+package message
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/fullpipe/icu-mf/parse/mf2"
+	"golang.org/x/text/language"
+)
+
+// BuildMF2 compiles MessageFormat 2 message to the same evaluables as Build.
+//
+// Supported functions are :string, :number and :integer.
+// As selectors, numbers match plural categories, ordinal categories
+// with select=ordinal, or exact values with select=exact.
+// :number style=percent formats percents, :integer rounds numbers half to even.
+// Other options are not supported and fail the build.
+// Markup is not rendered, as MessageFormat 2 formats it to nothing in plain text.
+func BuildMF2(in *mf2.Message, lang language.Tag, opts ...BuildOption) (Evalable, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	b := &mf2Builder{lang: lang, vars: map[string]mf2Value{}}
+	for _, d := range in.Declarations {
+		v, err := b.resolve(d.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.vars[d.Name] = v
+	}
+
+	if len(in.Selectors) == 0 {
+		return b.pattern(in.Pattern)
+	}
+
+	sels := make([]matchSelector, 0, len(in.Selectors))
+	for _, name := range in.Selectors {
+		v := b.vars[name]
+		if v.Function == nil || v.Variable == "" {
+			return nil, fmt.Errorf("selector $%s must be an annotated argument", name)
+		}
+
+		kind, err := selectorKindOf(v.Function)
+		if err != nil {
+			return nil, err
+		}
+
+		sels = append(sels, matchSelector{ArgName: v.Variable, Kind: kind, Integer: v.Function.Name == "integer"})
+	}
+
+	variants := make([]matchVariant, 0, len(in.Variants))
+	for _, v := range in.Variants {
+		eval, err := b.pattern(v.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]matchKey, 0, len(v.Keys))
+		for i, k := range v.Keys {
+			if err := checkKey(k, sels[i], b.vars[in.Selectors[i]].Function.Name); err != nil {
+				return nil, err
+			}
+
+			keys = append(keys, matchKey{Value: k.Value, Star: k.Star})
+		}
+
+		variants = append(variants, matchVariant{Keys: keys, Eval: eval})
+	}
+
+	return buildMatch(sels, variants, lang, o)
+}
+
+// mf2Value is an operand with its function, resolved through declarations.
+type mf2Value struct {
+	// Variable is argument name, it is empty for literals.
+	Variable string
+	Literal  string
+	Function *mf2.Function
+}
+
+type mf2Builder struct {
+	lang language.Tag
+	// declared variables
+	vars map[string]mf2Value
+}
+
+// resolve replaces declared variables with their values,
+// function of the expression overrides function of the declaration.
+func (b *mf2Builder) resolve(e *mf2.Expression) (mf2Value, error) {
+	var v mf2Value
+
+	switch {
+	case e.Operand == nil:
+		return v, fmt.Errorf("%w: :%s without operand", ErrUnsupportedFunction, e.Function.Name)
+	case e.Operand.Variable != "":
+		var ok bool
+		if v, ok = b.vars[e.Operand.Variable]; !ok {
+			v.Variable = e.Operand.Variable
+		}
+	default:
+		v.Literal = e.Operand.Literal
+	}
+
+	if e.Function != nil {
+		if err := checkOptions(e.Function); err != nil {
+			return v, err
+		}
+
+		v.Function = e.Function
+	}
+
+	return v, nil
+}
+
+// functionOptions are supported options of functions.
+var functionOptions = map[string][]string{
+	"string":  nil,
+	"number":  {"style", "select"},
+	"integer": {"select"},
+}
+
+// checkOptions fails on options that are not supported,
+// instead of formatting message differently than expected.
+// Unknown functions are reported where they are used.
+func checkOptions(fn *mf2.Function) error {
+	supported, ok := functionOptions[fn.Name]
+	if !ok {
+		return nil
+	}
+
+	for _, o := range fn.Options {
+		if !slices.Contains(supported, o.Name) {
+			return fmt.Errorf("%w: option %s of :%s", ErrUnsupportedFunction, o.Name, fn.Name)
+		}
+	}
+
+	return nil
+}
+
+func (b *mf2Builder) pattern(p mf2.Pattern) (Evalable, error) {
+	fragments := make([]Evalable, 0, len(p))
+	for _, part := range p {
+		switch {
+		case part.Expression != nil:
+			eval, err := b.placeholder(part.Expression)
+			if err != nil {
+				return nil, err
+			}
+
+			fragments = append(fragments, eval)
+		case part.Markup != nil:
+			// markup is formatted to nothing
+		default:
+			fragments = append(fragments, Content(part.Text))
+		}
+	}
+
+	switch len(fragments) {
+	case 0:
+		return Content(""), nil
+	case 1:
+		return fragments[0], nil
+	default:
+		return &Message{fragments: fragments}, nil
+	}
+}
+
+func (b *mf2Builder) placeholder(e *mf2.Expression) (Evalable, error) {
+	v, err := b.resolve(e)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := ""
+	if v.Function != nil {
+		fn = v.Function.Name
+	}
+
+	switch fn {
+	case "", "string":
+		if v.Variable == "" {
+			return Content(v.Literal), nil
+		}
+
+		return PlainArg(v.Variable), nil
+	case "number", "integer":
+		format := IntegerNumberFormat
+		if fn == "number" {
+			style, err := literalOption(v.Function, "style")
+			if err != nil {
+				return nil, err
+			}
+
+			switch style {
+			case "", "decimal":
+				format = NoneNumberFormat
+			case "percent":
+				format = PercentNumberFormat
+			default:
+				return nil, fmt.Errorf("%w: number style %s", ErrUnsupportedFunction, style)
+			}
+		}
+
+		n := NewNumber(v.Variable, format, b.lang)
+		n.round = fn == "integer"
+
+		if v.Variable != "" {
+			return n, nil
+		}
+
+		// literals are formatted once
+		res, err := n.Eval(Context{"": v.Literal})
+		if err != nil {
+			return nil, fmt.Errorf("invalid number literal %q", v.Literal)
+		}
+
+		return Content(res), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, fn)
+	}
+}
+
+func selectorKindOf(fn *mf2.Function) (selectorKind, error) {
+	switch fn.Name {
+	case "string":
+		return selectString, nil
+	case "number", "integer":
+		sel, err := literalOption(fn, "select")
+		if err != nil {
+			return 0, err
+		}
+
+		switch sel {
+		case "", "plural":
+			return selectPlural, nil
+		case "ordinal":
+			return selectOrdinal, nil
+		case "exact":
+			return selectString, nil
+		default:
+			return 0, fmt.Errorf("%w: select=%s", ErrUnsupportedFunction, sel)
+		}
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedFunction, fn.Name)
+	}
+}
+
+// checkKey fails on keys of plural selectors that are neither categories
+// nor integers, like 1.5, as only integers are matched exactly.
+func checkKey(k mf2.Key, sel matchSelector, fn string) error {
+	if k.Star || sel.Kind == selectString {
+		return nil
+	}
+
+	if _, ok := strToFormMap[k.Value]; ok {
+		return nil
+	}
+
+	if _, err := strconv.ParseUint(k.Value, 10, 64); err != nil {
+		return fmt.Errorf("key %s of :%s selector $%s is not supported, only plural categories and non-negative integers are", k.Value, fn, sel.ArgName)
+	}
+
+	return nil
+}
+
+// literalOption returns value of the option or "" if there is no such option.
+func literalOption(fn *mf2.Function, name string) (string, error) {
+	for _, o := range fn.Options {
+		if o.Name != name {
+			continue
+		}
+
+		if o.Value.Variable != "" {
+			return "", fmt.Errorf("option %s must be a literal", name)
+		}
+
+		return o.Value.Literal, nil
+	}
+
+	return "", nil
+}
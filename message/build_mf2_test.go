@@ -0,0 +1,142 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/fullpipe/icu-mf/parse/mf2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestBuildMF2(t *testing.T) {
+	const invitation = `.input {$gender :string}
+.input {$count :number}
+.match $gender $count
+female 0 {{She invited nobody}}
+female one {{She invited one guest}}
+female * {{She invited {$count} guests}}
+* 0 {{They invited nobody}}
+* one {{They invited one guest}}
+* * {{They invited {$count} guests}}`
+
+	tests := []struct {
+		name string
+		in   string
+		ctx  Context
+		want string
+	}{
+		{"simple", "Hello, {$name}!", Context{"name": "Bob"}, "Hello, Bob!"},
+		{"literals", "{|{a}|} {1234.5 :number} {0.5 :number style=percent}", nil, "{a} 1,234.5 50%"},
+		{"markup", "Click {#link}here{/link}{#br/}", nil, "Click here"},
+		{"input", ".input {$n :integer}\n{{{$n} files}}", Context{"n": 1234.7}, "1,235 files"},
+		{"integer rounds", "{$n :integer} {$m :integer} {3.5 :integer} {2.5 :integer}", Context{"n": 3.7, "m": -3.7}, "4 -4 4 2"},
+		{"local", ".local $n = {$count :number}\n{{{$n} files}}", Context{"count": 1234}, "1,234 files"},
+		{"local literal", ".local $x = {|lit|}\n{{{$x}}}", nil, "lit"},
+		{"match exact", invitation, Context{"gender": "female", "count": 0}, "She invited nobody"},
+		{"match category", invitation, Context{"gender": "female", "count": 1}, "She invited one guest"},
+		{"match star", invitation, Context{"gender": "female", "count": 5}, "She invited 5 guests"},
+		{"match second", invitation, Context{"gender": "male", "count": 1}, "They invited one guest"},
+		{"match fallback", invitation, Context{"gender": "male", "count": 7}, "They invited 7 guests"},
+		{
+			"first selector wins",
+			".input {$a :string} .input {$b :string}\n.match $a $b\nx * {{x*}}\n* y {{*y}}\n* * {{**}}",
+			Context{"a": "x", "b": "y"},
+			"x*",
+		},
+		{
+			"less preferred variant",
+			".input {$a :string} .input {$b :string}\n.match $a $b\nx y {{xy}}\n* z {{*z}}\n* * {{**}}",
+			Context{"a": "x", "b": "z"},
+			"*z",
+		},
+		{
+			"category is not other",
+			".input {$n :number} .input {$g :string}\n.match $n $g\nother x {{other x}}\n* * {{**}}",
+			Context{"n": 1, "g": "x"},
+			"**",
+		},
+		{
+			"integer plural",
+			".input {$n :integer}\n.match $n\none {{one}}\n* {{other}}",
+			Context{"n": 1.4},
+			"one",
+		},
+		{
+			"integer exact",
+			".input {$n :integer select=exact}\n.match $n\n2 {{two}}\n* {{other}}",
+			Context{"n": 1.6},
+			"two",
+		},
+		{
+			"other is a key",
+			".input {$g :string}\n.match $g\nother {{O}}\n* {{S}}",
+			Context{"g": "foo"},
+			"S",
+		},
+		{
+			"other key",
+			".input {$g :string}\n.match $g\nother {{O}}\n* {{S}}",
+			Context{"g": "other"},
+			"O",
+		},
+		{
+			"ordinal",
+			".input {$n :number select=ordinal}\n.match $n\none {{{$n}st}}\ntwo {{{$n}nd}}\nfew {{{$n}rd}}\n* {{{$n}th}}",
+			Context{"n": 22},
+			"22nd",
+		},
+		{
+			"exact",
+			".input {$n :integer select=exact}\n.match $n\n1 {{one}}\n* {{{$n}}}",
+			Context{"n": 21},
+			"21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := mf2.Parse(tt.in)
+			require.NoError(t, err)
+
+			eval, err := BuildMF2(msg, language.English)
+			require.NoError(t, err)
+
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = Context{}
+			}
+
+			got, err := eval.Eval(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildMF2_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"unknown function", "{$d :datetime}"},
+		{"unknown selector", ".input {$x :foo}\n.match $x\n* {{x}}"},
+		{"literal selector", ".local $x = {|a| :string}\n.match $x\n* {{x}}"},
+		{"invalid plural key", ".input {$n :number}\n.match $n\nlots {{a}}\n* {{b}}"},
+		{"fractional plural key", ".input {$n :number}\n.match $n\n1.5 {{a}}\n* {{b}}"},
+		{"variable option", "{$n :number style=$s}"},
+		{"unsupported option", "{$n :number minimumFractionDigits=2}"},
+		{"unsupported integer option", "{$n :integer style=percent}"},
+		{"unsupported declaration option", ".input {$n :number useGrouping=never}\n{{{$n}}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := mf2.Parse(tt.in)
+			require.NoError(t, err)
+
+			_, err = BuildMF2(msg, language.English)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -122,7 +122,7 @@ func Test_buildFragment(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			eval, err := buildFragment(tt.f, language.English)
+			eval, err := buildFragment(tt.f, language.English, buildOptions{})
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.Nil(t, eval)
@@ -135,3 +135,189 @@ func Test_buildFragment(t *testing.T) {
 		})
 	}
 }
+
+func TestBuild_UnsupportedFunction(t *testing.T) {
+	_, err := buildFragment(
+		parse.Fragment{Func: &parse.Func{ArgName: "foo", Func: "spellout"}},
+		language.English,
+		buildOptions{},
+	)
+	require.ErrorIs(t, err, ErrUnsupportedFunction)
+
+	_, err = buildFragment(
+		parse.Fragment{Func: &parse.Func{ArgName: "foo", Func: "number", Param: "scientific"}},
+		language.English,
+		buildOptions{},
+	)
+	require.ErrorIs(t, err, ErrUnsupportedFunction)
+}
+
+// Apostrophe cases from ICU MessageFormat and MessagePattern docs, DOUBLE_OPTIONAL mode.
+func TestBuild_ICUApostrophes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"This '{isn''t}' obvious", "This {isn't} obvious"},
+		{"I don't know", "I don't know"},
+		{"I don''t know", "I don't know"},
+		{"'{name}'", "{name}"},
+		{"'{foo} is literal'", "{foo} is literal"},
+		{"'{'", "{"},
+		{"'}'", "}"},
+		{"}", "}"},
+		{"''", "'"},
+		{"''{name}''", "'Bob'"},
+		{"'''{'''", "'{'"},
+		{"It'", "It'"},
+		{"'{unclosed {name}", "{unclosed {name}"},
+		{"'#' #", "'#' #"},
+		{"{count, plural, other {# is '#'}}", "3 is #"},
+		{"{count, plural, other {'{'#'}'}}", "{3}"},
+		{"{count, plural, other {it''s #}}", "it's 3"},
+		{"{count, plural, other {it's #}}", "it's 3"},
+		{"{count, selectordinal, other {#th '#'}}", "3th #"},
+		{"{gender, select, other {# '#' x}}", "# '#' x"},
+		{"{gender, select, other {'{' '}'}}", "{ }"},
+		{"{gender, select, other {'}}'}}", "}}"},
+		{"{count, plural, other {{gender, select, other {#}}}}", "#"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			msg, err := parse.Parse(tt.in)
+			require.NoError(t, err)
+
+			eval, err := Build(*msg, language.English)
+			require.NoError(t, err)
+
+			got, err := eval.Eval(Context{"name": "Bob", "count": 3, "gender": "other"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuild_MultiSelect(t *testing.T) {
+	const invitation = `{guests, host_gender, plural select, offset:1
+		=0 other {{host} does not give a party.}
+		=1 female {{host} invites {guest} to her party.}
+		=1 male {{host} invites {guest} to his party.}
+		=1 other {{host} invites {guest} to their party.}
+		one other {{host} invites {guest} and one other person to their party.}
+		other female {{host} invites {guest} and # other people to her party.}
+		other other {{host} invites {guest} and # other people to their party.}
+	}`
+
+	tests := []struct {
+		name   string
+		gender string
+		guests int
+		want   string
+	}{
+		{"exact", "female", 1, "Ann invites Bob to her party."},
+		{"exact other gender", "none", 1, "Ann invites Bob to their party."},
+		{"exact any gender", "female", 0, "Ann does not give a party."},
+		{"category after offset", "male", 2, "Ann invites Bob and one other person to their party."},
+		{"first selector is preferred", "female", 2, "Ann invites Bob and one other person to their party."},
+		{"other female", "female", 5, "Ann invites Bob and 4 other people to her party."},
+		{"other other", "male", 5, "Ann invites Bob and 4 other people to their party."},
+	}
+
+	msg, err := parse.Parse(invitation)
+	require.NoError(t, err)
+
+	eval, err := Build(*msg, language.English)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := eval.Eval(Context{
+				"host":        "Ann",
+				"guest":       "Bob",
+				"host_gender": tt.gender,
+				"guests":      tt.guests,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, in := range []string{
+		"{a, b, select plural, x one {y}}",
+		"{a, b, select number, other other {y}}",
+		"{a, b, select plural, x lots {y} other other {z}}",
+		"{a, b, select plural, x one {y} x one {z} other other {z}}",
+	} {
+		msg, err := parse.Parse(in)
+		require.NoError(t, err, in)
+
+		_, err = Build(*msg, language.English)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestBuild_Choice(t *testing.T) {
+	const files = "{n, choice, -1#negative|0#no files|1#one file|1<{n, number} files|1000≤'|'many'|'}"
+
+	tests := []struct {
+		n    any
+		want string
+	}{
+		{-5, "negative"},
+		{0, "no files"},
+		{0.5, "no files"},
+		{1, "one file"},
+		{1.5, "1.5 files"},
+		{42, "42 files"},
+		{1000, "|many|"},
+	}
+
+	msg, err := parse.Parse(files)
+	require.NoError(t, err)
+
+	eval, err := Build(*msg, language.English)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		got, err := eval.Eval(Context{"n": tt.n})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.n)
+	}
+
+	assert.Equal(t, []ArgInfo{
+		{Name: "n", Kind: ArgNumber},
+		{Name: "n", Kind: ArgNumber, Path: []PathStep{{Arg: "n", Case: "1<"}}},
+	}, Args(eval))
+
+	for _, in := range []string{
+		"{n, choice, 1#a|0#b}",
+		"{n, choice, 1<a|1#b}",
+		"{n, choice, 1#a|1#b}",
+	} {
+		msg, err := parse.Parse(in)
+		require.NoError(t, err, in)
+
+		_, err = Build(*msg, language.English)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestBuild_Rewritten(t *testing.T) {
+	msg, err := parse.Parse("{gender, select, male {He} other {They}} left")
+	require.NoError(t, err)
+
+	parse.Rewrite(msg, func(n parse.Node) parse.Node {
+		if m, ok := n.(*parse.Message); ok && m.String() == "He" {
+			return nil
+		}
+
+		return n
+	})
+
+	eval, err := Build(*msg, language.English)
+	require.NoError(t, err)
+
+	got, err := eval.Eval(Context{"gender": "male"})
+	require.NoError(t, err)
+	assert.Equal(t, " left", got, "case of removed message is empty")
+}
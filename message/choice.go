@@ -0,0 +1,54 @@
+package message
+
+// { COUNT, choice,
+//
+//	    0#There are no files.
+//	   |1#There is one file.
+//	   |1<There are {COUNT, number} files.
+//	}
+//
+// Choice is legacy ICU ChoiceFormat, prefer Plural in new messages.
+// The last case with limit not greater than the number is selected,
+// or with limit less than the number for strict limits.
+// Numbers below the first limit select the first case.
+type Choice struct {
+	ArgName string
+	Cases   []ChoiceCase
+}
+
+// ChoiceCase is a case of Choice, limits of cases are ascending.
+type ChoiceCase struct {
+	// Key is case name as written, like 0# or 1<.
+	Key   string
+	Limit float64
+	// Strict is true for < limits.
+	Strict bool
+	Eval   Evalable
+}
+
+func (c *Choice) Eval(ctx Context) (string, error) {
+	return c.evalEnv(ctx, Env{})
+}
+
+func (c *Choice) evalEnv(ctx Context, env Env) (string, error) {
+	v, err := ctx.Float64(c.ArgName)
+	if err != nil {
+		return "", err
+	}
+
+	match := c.Cases[0]
+	for _, cc := range c.Cases[1:] {
+		if v < cc.Limit || (cc.Strict && v == cc.Limit) {
+			break
+		}
+
+		match = cc
+	}
+
+	return EvalEnv(match.Eval, ctx, env)
+}
+
+var (
+	_ Evalable    = (*Choice)(nil)
+	_ envEvalable = (*Choice)(nil)
+)
@@ -1,10 +1,14 @@
 package message
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"reflect"
 	"strconv"
 	"time"
+
+	"golang.org/x/text/currency"
 )
 
 // type Context interface {
@@ -17,16 +21,62 @@ type Context map[string]any
 func (c Context) String(name string) (string, error) {
 	v, ok := c[name]
 	if !ok {
-		return "", fmt.Errorf("argument %s not exists", name)
+		return "", missingArgError(name)
 	}
 
 	return fmt.Sprint(v), nil
 }
 
+// SelectKey converts argument to a key of a select case.
+// Booleans become "true" or "false", fmt.Stringer values use String(),
+// unless they are nil, numbers are formatted without exponent or trailing zeros.
+func (c Context) SelectKey(name string) (string, error) {
+	v, ok := c[name]
+	if !ok {
+		return "", missingArgError(name)
+	}
+
+	rv := reflect.ValueOf(v)
+	if s, ok := v.(fmt.Stringer); ok {
+		// String() of nil receivers usually panics
+		if isNil(rv) {
+			return "", argTypeError(name, fmt.Errorf("unable to use nil %T as select key", v))
+		}
+
+		return s.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	default:
+		return "", argTypeError(name, fmt.Errorf("unable to use %v as select key", v))
+	}
+}
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
 func (c Context) Int64(key string) (int64, error) {
 	v, ok := c[key]
 	if !ok {
-		return 0, fmt.Errorf("argument %s not exists", key)
+		return 0, missingArgError(key)
 	}
 
 	switch i := v.(type) {
@@ -42,7 +92,7 @@ func (c Context) Int64(key string) (int64, error) {
 		return int64(i), nil
 	case uint:
 		if i > math.MaxInt64 {
-			return 0, fmt.Errorf("unable to convert uint %v to int64 from arg %s", v, key)
+			return 0, argTypeError(key, fmt.Errorf("unable to convert uint %v to int64", v))
 		}
 
 		return int64(i), nil //nolint:gosec
@@ -54,7 +104,7 @@ func (c Context) Int64(key string) (int64, error) {
 		return int64(i), nil
 	case uint64:
 		if i > math.MaxInt64 {
-			return 0, fmt.Errorf("unable to convert uint64 %v to int64 from arg %s", v, key)
+			return 0, argTypeError(key, fmt.Errorf("unable to convert uint64 %v to int64", v))
 		}
 
 		return int64(i), nil //nolint:gosec
@@ -65,19 +115,19 @@ func (c Context) Int64(key string) (int64, error) {
 	case string:
 		fl, err := strconv.ParseFloat(i, 64)
 		if err != nil {
-			return 0, err
+			return 0, argTypeError(key, err)
 		}
 
 		return int64(fl), nil
 	default:
-		return 0, fmt.Errorf("unable to convert %v to int64 from arg %s", v, key)
+		return 0, argTypeError(key, fmt.Errorf("unable to convert %v to int64", v))
 	}
 }
 
 func (c Context) Float64(key string) (float64, error) {
 	v, ok := c[key]
 	if !ok {
-		return 0, fmt.Errorf("argument %s not exists", key)
+		return 0, missingArgError(key)
 	}
 
 	switch i := v.(type) {
@@ -108,24 +158,24 @@ func (c Context) Float64(key string) (float64, error) {
 	case string:
 		fl, err := strconv.ParseFloat(i, 64)
 		if err != nil {
-			return 0, err
+			return 0, argTypeError(key, err)
 		}
 
 		return fl, nil
 	default:
-		return 0, fmt.Errorf("unable to convert %v to float from arg %s", v, key)
+		return 0, argTypeError(key, fmt.Errorf("unable to convert %v to float", v))
 	}
 }
 
 func (c Context) Time(key string) (time.Time, error) {
 	v, ok := c[key]
 	if !ok {
-		return time.Time{}, fmt.Errorf("argument %s not exists", key)
+		return time.Time{}, missingArgError(key)
 	}
 
 	t, ok := v.(time.Time)
 	if !ok {
-		return time.Time{}, fmt.Errorf("argument %s is not a time.Time", key)
+		return time.Time{}, argTypeError(key, fmt.Errorf("%v is not a time.Time", v))
 	}
 
 	return t, nil
@@ -134,7 +184,7 @@ func (c Context) Time(key string) (time.Time, error) {
 func (c Context) Any(name string) (any, error) {
 	v, ok := c[name]
 	if !ok {
-		return "", fmt.Errorf("argument %s not exists", name)
+		return "", missingArgError(name)
 	}
 
 	return v, nil
@@ -144,4 +194,40 @@ func (c Context) Set(name string, value any) {
 	c[name] = value
 }
 
+// Env is what a message is evaluated with besides arguments,
+// request-scoped options like cancellation, timezone and currency.
+type Env struct {
+	// Context stops evaluation once it is done.
+	Context context.Context
+	// Location is timezone for date and time arguments, nil keeps their own.
+	Location *time.Location
+	// Currency is for {amount, number, currency} arguments,
+	// the zero value is the currency of the message language region.
+	Currency currency.Unit
+}
+
+// Err returns error of the Context, if it is done.
+func (e Env) Err() error {
+	if e.Context != nil {
+		return e.Context.Err()
+	}
+
+	return nil
+}
+
+// envEvalable is implemented by evaluables of this package, they pass Env to nested ones.
+type envEvalable interface {
+	evalEnv(ctx Context, env Env) (string, error)
+}
+
+// EvalEnv evaluates e with arguments and Env. Evalables that do not
+// use Env, like ones of other packages, are evaluated with Eval.
+func EvalEnv(e Evalable, ctx Context, env Env) (string, error) {
+	if e, ok := e.(envEvalable); ok {
+		return e.evalEnv(ctx, env)
+	}
+
+	return e.Eval(ctx)
+}
+
 // var _ Context = &context{}
@@ -1,10 +1,16 @@
 package message
 
 import (
+	"context"
 	"math"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
 )
 
 func Test_context_AsString(t *testing.T) {
@@ -417,3 +423,70 @@ func TestContext_Time(t *testing.T) {
 		})
 	}
 }
+
+type testStatus string
+
+func TestContext_SelectKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       Context
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"string", Context{"foo": "bar"}, "foo", "bar", false},
+		{"named string", Context{"foo": testStatus("active")}, "foo", "active", false},
+		{"bool true", Context{"foo": true}, "foo", "true", false},
+		{"bool false", Context{"foo": false}, "foo", "false", false},
+		{"int", Context{"foo": -42}, "foo", "-42", false},
+		{"uint64", Context{"foo": uint64(math.MaxUint64)}, "foo", "18446744073709551615", false},
+		{"float64", Context{"foo": 3.0}, "foo", "3", false},
+		{"float32", Context{"foo": float32(0.1)}, "foo", "0.1", false},
+		{"stringer", Context{"foo": time.Duration(0)}, "foo", "0s", false},
+		{"pointer stringer", Context{"foo": &testShade{name: "light"}}, "foo", "light", false},
+		{"nil stringer", Context{"foo": (*testShade)(nil)}, "foo", "", true},
+		{"unknown type", Context{"foo": []byte("bar")}, "foo", "", true},
+		{"unknown name", Context{"foo": 42}, "bar", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.SelectKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Context.SelectKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Context.SelectKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalEnv(t *testing.T) {
+	eval := &Select{ArgName: "g", Cases: map[string]Evalable{
+		DefaultCase: &Message{fragments: []Evalable{
+			NewNumber("n", CurrencyNumberFormat, language.English),
+			Content(" on "),
+			NewDatetime("t", LongDatetimeFormat, language.English),
+		}},
+	}}
+	c := Context{"n": 1234.5, "t": time.Date(1961, 4, 12, 6, 7, 3, 0, time.UTC)}
+
+	got, err := eval.Eval(c)
+	require.NoError(t, err)
+	assert.Equal(t, "$ 1,234.50 on April 12, 1961 at 6:07:03 AM UTC", got)
+
+	got, err = EvalEnv(eval, c, Env{Location: time.FixedZone("MSK", 3*60*60), Currency: currency.EUR})
+	require.NoError(t, err)
+	assert.Equal(t, "€ 1,234.50 on April 12, 1961 at 9:07:03 AM MSK", got, "env of nested evaluables")
+	assert.Len(t, c, 2, "env is not an argument")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EvalEnv(eval, c, Env{Context: ctx})
+	require.ErrorIs(t, err, context.Canceled)
+
+	got, err = EvalEnv(Content("a"), c, Env{Context: ctx})
+	require.NoError(t, err, "evaluables without env")
+	assert.Equal(t, "a", got)
+}
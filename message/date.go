@@ -81,12 +81,23 @@ func NewDate(argName string, format DatetimeFormat, lang language.Tag) *Datetime
 }
 
 func (dt Datetime) Eval(ctx Context) (string, error) {
+	return dt.evalEnv(ctx, Env{})
+}
+
+func (dt Datetime) evalEnv(ctx Context, env Env) (string, error) {
 	d, err := ctx.Time(dt.argName)
 	if err != nil {
 		return "", err
 	}
 
+	if loc := env.Location; loc != nil {
+		d = d.In(loc)
+	}
+
 	return d.Format(dt.format), nil
 }
 
-var _ Evalable = (*Datetime)(nil)
+var (
+	_ Evalable    = (*Datetime)(nil)
+	_ envEvalable = (*Datetime)(nil)
+)
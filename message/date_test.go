@@ -147,3 +147,13 @@ func TestDatetime_Eval(t *testing.T) {
 		})
 	}
 }
+
+func TestDatetime_EvalLocation(t *testing.T) {
+	dt := NewDatetime("foo", LongDatetimeFormat, language.English)
+
+	ctx := Context{"foo": time.Date(1961, 4, 12, 6, 7, 3, 0, time.UTC)}
+
+	got, err := EvalEnv(dt, ctx, Env{Location: time.FixedZone("MSK", 3*60*60)})
+	require.NoError(t, err)
+	assert.Equal(t, "April 12, 1961 at 9:07:03 AM MSK", got)
+}
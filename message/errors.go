@@ -0,0 +1,45 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrArgumentMissing     = errors.New("missing argument")
+	ErrArgumentType        = errors.New("invalid argument type")
+	ErrUnsupportedFunction = errors.New("unsupported function")
+)
+
+// ArgumentError describes a problem with a single message argument.
+// Kind is ErrArgumentMissing or ErrArgumentType,
+// Err is an optional underlying cause, like strconv.ErrSyntax.
+type ArgumentError struct {
+	Arg  string
+	Kind error
+	Err  error
+}
+
+func (e *ArgumentError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%v %s", e.Kind, e.Arg)
+	}
+
+	return fmt.Sprintf("%v %s: %v", e.Kind, e.Arg, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+func missingArgError(name string) error {
+	return &ArgumentError{Arg: name, Kind: ErrArgumentMissing}
+}
+
+func argTypeError(name string, err error) error {
+	return &ArgumentError{Arg: name, Kind: ErrArgumentType, Err: err}
+}
@@ -0,0 +1,33 @@
+package message
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestArgumentError(t *testing.T) {
+	_, err := Context{}.Any("foo")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrArgumentMissing)
+	assert.NotErrorIs(t, err, ErrArgumentType)
+	assert.Equal(t, "missing argument foo", err.Error())
+
+	_, err = Context{"foo": "bar"}.Float64("foo")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrArgumentType)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+	var argErr *ArgumentError
+	require.ErrorAs(t, err, &argErr)
+	assert.Equal(t, "foo", argErr.Arg)
+
+	_, err = NewPlural("num", language.English, 0).Eval(Context{"num": []byte("1")})
+	require.ErrorAs(t, err, &argErr)
+	assert.Equal(t, "num", argErr.Arg)
+	assert.True(t, errors.Is(err, ErrArgumentType))
+}
@@ -0,0 +1,267 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// selectorKind is how a selector of several selectors matches its keys.
+type selectorKind int
+
+const (
+	// selectString matches keys exactly, like select.
+	selectString selectorKind = iota
+	// selectPlural matches =N or a plural category, like plural.
+	selectPlural
+	// selectOrdinal is selectPlural with ordinal categories.
+	selectOrdinal
+)
+
+type matchSelector struct {
+	ArgName string
+	Kind    selectorKind
+	// Offset of plural selectors
+	Offset int
+	// DefaultOther is true if 'other' key of string selectors matches anything,
+	// like in ICU select. Otherwise it is a key like any other and only * does,
+	// like in MessageFormat 2.
+	DefaultOther bool
+	// Integer rounds the argument before matching, like MF2 :integer.
+	Integer bool
+}
+
+// matchKey is a key of a variant, Star matches anything.
+type matchKey struct {
+	Value string
+	Star  bool
+}
+
+type matchVariant struct {
+	Keys []matchKey
+	Eval Evalable
+}
+
+// buildMatch compiles variants of several selectors to nested Select and Plural.
+// Like MessageFormat 2, the first selector is the most significant:
+// for every selector an exact key is preferred to a plural category,
+// and a category to *. If the preferred variants do not match
+// the rest of the selectors, less preferred ones are tried.
+// For string selectors of ICU "other" is the default case, like in select,
+// MessageFormat 2 ones fall back to * only.
+// Variants must have a key per selector, and there must be a variant
+// with * for every selector.
+func buildMatch(sels []matchSelector, variants []matchVariant, lang language.Tag, o buildOptions) (Evalable, error) {
+	if len(sels) == 0 {
+		return variants[0].Eval, nil
+	}
+
+	sel, rest := sels[0], sels[1:]
+
+	var (
+		keys  []string
+		byKey = map[string][]matchVariant{}
+		stars []matchVariant
+	)
+
+	for _, v := range variants {
+		k := v.Keys[0]
+		v.Keys = v.Keys[1:]
+
+		if k.Star {
+			stars = append(stars, v)
+
+			continue
+		}
+
+		if _, ok := byKey[k.Value]; !ok {
+			keys = append(keys, k.Value)
+		}
+
+		byKey[k.Value] = append(byKey[k.Value], v)
+	}
+
+	// chain tries groups of variants in order
+	chain := func(groups ...[]matchVariant) (Evalable, error) {
+		var alts firstOf
+		for _, g := range groups {
+			if len(g) == 0 {
+				continue
+			}
+
+			e, err := buildMatch(rest, g, lang, o)
+			if err != nil {
+				return nil, err
+			}
+
+			// the last selector always matches
+			if len(rest) == 0 {
+				return e, nil
+			}
+
+			alts = append(alts, e)
+		}
+
+		if len(alts) == 1 {
+			return alts[0], nil
+		}
+
+		return alts, nil
+	}
+
+	if sel.Kind == selectString {
+		eval := &Select{
+			ArgName: sel.ArgName,
+			Cases:   make(map[string]Evalable, len(keys)+1),
+			Strict:  o.strictSelect,
+			integer: sel.Integer,
+		}
+
+		for _, k := range keys {
+			c, err := chain(byKey[k], stars)
+			if err != nil {
+				return nil, err
+			}
+
+			eval.Cases[k] = c
+		}
+
+		if len(stars) == 0 {
+			eval.star = noMatch{}
+
+			return eval, nil
+		}
+
+		c, err := chain(stars)
+		if err != nil {
+			return nil, err
+		}
+
+		// 'other' keys of ICU are stars, so there is no such case yet
+		if sel.DefaultOther {
+			eval.Cases[DefaultCase] = c
+		} else {
+			eval.star = c
+		}
+
+		return eval, nil
+	}
+
+	eval, categories := NewPlural(sel.ArgName, lang, sel.Offset), PluralCategories(lang)
+	if sel.Kind == selectOrdinal {
+		eval, categories = NewSelectOrdinal(sel.ArgName, lang, sel.Offset), OrdinalCategories(lang)
+	}
+
+	eval.integer = sel.Integer
+
+	for _, k := range keys {
+		if form, ok := strToFormMap[k]; ok {
+			c, err := chain(byKey[k], stars)
+			if err != nil {
+				return nil, err
+			}
+
+			eval.Cases[form] = c
+
+			continue
+		}
+
+		n, err := strconv.ParseUint(strings.TrimPrefix(k, "="), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plural case %s", k)
+		}
+
+		// =N is preferred to its category, which is matched after offset
+		form := eval.formFunc(lang, max(int(n)-sel.Offset, 0), 0, 0, 0, 0) //nolint: gosec
+		c, err := chain(byKey[k], byKey[formToStr(form)], stars)
+		if err != nil {
+			return nil, err
+		}
+
+		eval.EqCases[n] = c
+	}
+
+	// missing categories must not fall back to the 'other' case
+	for _, name := range categories {
+		if _, ok := eval.Cases[strToFormMap[name]]; ok {
+			continue
+		}
+
+		if len(stars) == 0 {
+			eval.Cases[strToFormMap[name]] = noMatch{}
+
+			continue
+		}
+
+		c, err := chain(stars)
+		if err != nil {
+			return nil, err
+		}
+
+		// * is the other case, any other category is not written
+		eval.Cases[strToFormMap[name]] = c
+		if name != DefaultCase {
+			eval.starCases = append(eval.starCases, strToFormMap[name])
+		}
+	}
+
+	return eval, nil
+}
+
+func formToStr(form plural.Form) string {
+	for name, f := range strToFormMap {
+		if f == form {
+			return name
+		}
+	}
+
+	return DefaultCase
+}
+
+// errNoMatch is returned by evaluables of several selectors, when no variant matches.
+var errNoMatch = errors.New("no matching variant")
+
+// firstOf evaluates alternatives in order until one of them has a matching case.
+type firstOf []Evalable
+
+func (f firstOf) Eval(ctx Context) (string, error) {
+	return f.evalEnv(ctx, Env{})
+}
+
+func (f firstOf) evalEnv(ctx Context, env Env) (string, error) {
+	for _, e := range f {
+		res, err := EvalEnv(e, ctx, env)
+		if !errors.Is(err, errNoMatch) {
+			return res, err
+		}
+	}
+
+	return "", errNoMatch
+}
+
+func (f firstOf) Args() []ArgInfo {
+	var args []ArgInfo
+	for _, e := range f {
+		args = append(args, Args(e)...)
+	}
+
+	return args
+}
+
+// noMatch is a case without variants, less preferred variants are tried instead.
+type noMatch struct{}
+
+func (noMatch) Eval(Context) (string, error) {
+	return "", errNoMatch
+}
+
+var (
+	_ Evalable    = firstOf(nil)
+	_ envEvalable = firstOf(nil)
+	_ ArgLister   = firstOf(nil)
+	_ Evalable    = noMatch{}
+)
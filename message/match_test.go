@@ -0,0 +1,23 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstOf_Eval(t *testing.T) {
+	f := firstOf{noMatch{}, Content("b")}
+
+	got, err := f.Eval(Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "b", got, "next alternative on no match")
+
+	_, err = firstOf{noMatch{}}.Eval(Context{})
+	require.ErrorIs(t, err, errNoMatch)
+
+	f = firstOf{&Select{ArgName: "a", Cases: map[string]Evalable{"x": Content("x")}}, Content("b")}
+	_, err = f.Eval(Context{"a": "y"})
+	require.ErrorIs(t, err, ErrNoDefaultCase, "errors of alternatives are not a fallback")
+}
@@ -14,10 +14,18 @@ type Message struct {
 }
 
 func (m *Message) Eval(ctx Context) (string, error) {
+	return m.evalEnv(ctx, Env{})
+}
+
+func (m *Message) evalEnv(ctx Context, env Env) (string, error) {
 	var builder strings.Builder
 
 	for _, child := range m.fragments {
-		childRes, err := child.Eval(ctx)
+		if err := env.Err(); err != nil {
+			return "", err
+		}
+
+		childRes, err := EvalEnv(child, ctx, env)
 		if err != nil {
 			return "", err
 		}
@@ -60,7 +68,8 @@ type FunctionArg struct {
 }
 
 var (
-	_ Evalable = (*Content)(nil)
-	_ Evalable = (*Message)(nil)
-	_ Evalable = (*PlainArg)(nil)
+	_ Evalable    = (*Content)(nil)
+	_ Evalable    = (*Message)(nil)
+	_ envEvalable = (*Message)(nil)
+	_ Evalable    = (*PlainArg)(nil)
 )
@@ -1,6 +1,9 @@
 package message
 
 import (
+	"math"
+
+	"golang.org/x/text/currency"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 	"golang.org/x/text/number"
@@ -11,12 +14,17 @@ type Number struct {
 	Format  NumberFormat
 	Lang    language.Tag
 	printer *message.Printer
+
+	// round rounds arguments of IntegerNumberFormat half to even,
+	// like MF2 :integer, instead of truncating them.
+	round bool
 }
 
 var strToNumberFormatMap = map[string]NumberFormat{
-	"":        NoneNumberFormat,
-	"integer": IntegerNumberFormat,
-	"percent": PercentNumberFormat,
+	"":         NoneNumberFormat,
+	"integer":  IntegerNumberFormat,
+	"percent":  PercentNumberFormat,
+	"currency": CurrencyNumberFormat,
 }
 
 func NewNumber(argName string, format NumberFormat, lang language.Tag) *Number {
@@ -34,9 +42,14 @@ const (
 	NoneNumberFormat NumberFormat = iota
 	IntegerNumberFormat
 	PercentNumberFormat
+	CurrencyNumberFormat
 )
 
 func (n Number) Eval(ctx Context) (string, error) {
+	return n.evalEnv(ctx, Env{})
+}
+
+func (n Number) evalEnv(ctx Context, env Env) (string, error) {
 	switch n.Format {
 	case NoneNumberFormat:
 		v, err := ctx.Float64(n.ArgName)
@@ -47,6 +60,10 @@ func (n Number) Eval(ctx Context) (string, error) {
 		return n.printer.Sprint(number.Decimal(v)), nil
 	case IntegerNumberFormat:
 		v, err := ctx.Int64(n.ArgName)
+		if n.round {
+			v, err = roundedInt64(ctx, n.ArgName)
+		}
+
 		if err != nil {
 			return "", err
 		}
@@ -59,6 +76,18 @@ func (n Number) Eval(ctx Context) (string, error) {
 		}
 
 		return n.printer.Sprint(number.Percent(v, number.MaxFractionDigits(2))), nil
+	case CurrencyNumberFormat:
+		v, err := ctx.Float64(n.ArgName)
+		if err != nil {
+			return "", err
+		}
+
+		cur := env.Currency
+		if cur == (currency.Unit{}) {
+			cur, _ = currency.FromTag(n.Lang)
+		}
+
+		return n.printer.Sprint(currency.Symbol(cur.Amount(v))), nil
 	}
 
 	v, err := ctx.Float64(n.ArgName)
@@ -69,4 +98,22 @@ func (n Number) Eval(ctx Context) (string, error) {
 	return n.printer.Sprint(number.Decimal(v)), nil
 }
 
-var _ Evalable = (*Number)(nil)
+// roundedInt64 returns argument rounded half to even, e.g. 2.5 is 2 and 3.7 is 4.
+func roundedInt64(ctx Context, key string) (int64, error) {
+	switch ctx[key].(type) {
+	case float32, float64, string:
+		v, err := ctx.Float64(key)
+		if err != nil {
+			return 0, err
+		}
+
+		return int64(math.RoundToEven(v)), nil
+	default:
+		return ctx.Int64(key)
+	}
+}
+
+var (
+	_ Evalable    = (*Number)(nil)
+	_ envEvalable = (*Number)(nil)
+)
@@ -23,6 +23,14 @@ func TestNumber_Eval(t *testing.T) {
 			"3",
 			false,
 		},
+		{
+			"float is truncated",
+			"n",
+			IntegerNumberFormat,
+			Context{"n": 3.7},
+			"3",
+			false,
+		},
 		{
 			"string float to integer format",
 			"n",
@@ -88,6 +96,22 @@ func TestNumber_Eval(t *testing.T) {
 			"3.14",
 			false,
 		},
+		{
+			"currency of language region",
+			"n",
+			CurrencyNumberFormat,
+			Context{"n": 1234.5},
+			"$ 1,234.50",
+			false,
+		},
+		{
+			"error on invalid arg",
+			"n",
+			CurrencyNumberFormat,
+			Context{"n": "foo"},
+			"",
+			true,
+		},
 		{
 			"error on invalid arg",
 			"n",
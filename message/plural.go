@@ -3,6 +3,7 @@ package message
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -22,7 +23,13 @@ type Plural struct {
 	Offset   int
 	EqCases  map[uint64]Evalable
 	Cases    map[plural.Form]Evalable
+	Ordinal  bool // selectordinal
 	formFunc func(lang language.Tag, i int, v int, w int, f int, t int) plural.Form
+	// starCases are categories of several selectors, matched by * variants,
+	// they are not listed by Args.
+	starCases []plural.Form
+	// integer rounds the argument before matching, like MF2 :integer.
+	integer bool
 }
 
 // NewPlural creates a new Plural for cardinal plurals.
@@ -32,7 +39,10 @@ func NewPlural(argName string, lang language.Tag, offset int) *Plural {
 
 // NewSelectOrdinal creates a new Plural for ordinal plurals.
 func NewSelectOrdinal(argName string, lang language.Tag, offset int) *Plural {
-	return newPlural(argName, lang, offset, plural.Ordinal.MatchPlural)
+	p := newPlural(argName, lang, offset, plural.Ordinal.MatchPlural)
+	p.Ordinal = true
+
+	return p
 }
 
 // newPlural is a helper function to create a new Plural.
@@ -61,6 +71,114 @@ var strToFormMap = map[string]plural.Form{
 	"many":      plural.Many,
 }
 
+// PluralCategories returns CLDR cardinal plural categories of the language
+// in CLDR order, e.g. one, few, many and other for Russian.
+func PluralCategories(lang language.Tag) []string {
+	return categories(lang, plural.Cardinal.MatchPlural)
+}
+
+// OrdinalCategories returns CLDR ordinal plural categories of the language,
+// e.g. one, two, few and other for English.
+func OrdinalCategories(lang language.Tag) []string {
+	return categories(lang, plural.Ordinal.MatchPlural)
+}
+
+// formOrder is CLDR order of plural categories.
+var formOrder = []string{"zero", "one", "two", "few", "many", DefaultCase}
+
+// categories probes formFunc with sample numbers, as x/text does not expose
+// categories of a language.
+func categories(lang language.Tag, formFunc func(lang language.Tag, i, v, w, f, t int) plural.Form) []string {
+	seen := map[plural.Form]bool{plural.Other: true}
+
+	samples(func(i, v, w, f, t int) {
+		seen[formFunc(lang, i, v, w, f, t)] = true
+	})
+
+	cats := make([]string, 0, len(seen))
+	for _, name := range formOrder {
+		if seen[strToFormMap[name]] {
+			cats = append(cats, name)
+		}
+	}
+
+	return cats
+}
+
+// ExactCategories returns plural categories of the language, all sample numbers
+// of which are matched by =N cases, e.g. one by =1 in English, but not in Russian,
+// where one is also 21. Categories are matched after offset and =N before it.
+func ExactCategories(lang language.Tag, ordinal bool, offset int, exact []uint64) []string {
+	formFunc := plural.Cardinal.MatchPlural
+	if ordinal {
+		formFunc = plural.Ordinal.MatchPlural
+	}
+
+	seen := map[plural.Form]bool{}
+	uncovered := map[plural.Form]bool{}
+
+	samples(func(i, v, w, f, t int) {
+		form := formFunc(lang, i, v, w, f, t)
+		seen[form] = true
+
+		if v > 0 || !slices.Contains(exact, uint64(i+offset)) { //nolint: gosec
+			uncovered[form] = true
+		}
+	})
+
+	var cats []string
+	for _, name := range formOrder {
+		if form := strToFormMap[name]; seen[form] && !uncovered[form] {
+			cats = append(cats, name)
+		}
+	}
+
+	return cats
+}
+
+// samples calls yield with plural operands of sample numbers:
+// integers below 1000, some large ones and decimals.
+func samples(yield func(i, v, w, f, t int)) {
+	for i := range 1000 {
+		yield(i, 0, 0, 0, 0)
+	}
+
+	for _, i := range []int{10_000, 100_000, 1_000_000, 10_000_000} {
+		yield(i, 0, 0, 0, 0)
+	}
+
+	// decimals with one and two visible fraction digits
+	for i := range 20 {
+		for f := range 100 {
+			yield(i, 2, fractionWidth(f), f, trimZeros(f))
+			if f < 10 {
+				yield(i, 1, fractionWidth(f*10), f, f)
+			}
+		}
+	}
+}
+
+// fractionWidth returns number of visible fraction digits without trailing zeros
+// for two digit fraction f.
+func fractionWidth(f int) int {
+	switch {
+	case f == 0:
+		return 0
+	case f%10 == 0:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func trimZeros(f int) int {
+	for f != 0 && f%10 == 0 {
+		f /= 10
+	}
+
+	return f
+}
+
 type PluralCase struct {
 	Key  string
 	Eval Evalable
@@ -72,14 +190,22 @@ type pm struct {
 }
 
 func (p *Plural) Eval(ctx Context) (string, error) {
+	return p.evalEnv(ctx, Env{})
+}
+
+func (p *Plural) evalEnv(ctx Context, env Env) (string, error) {
 	num, err := ctx.Any(p.ArgName)
+	if p.integer {
+		num, err = roundedInt64(ctx, p.ArgName)
+	}
+
 	if err != nil {
 		return "", err
 	}
 
 	np, err := toPluralForm(num)
 	if err != nil {
-		return "", err
+		return "", argTypeError(p.ArgName, err)
 	}
 
 	// FIX: plurals could be nested
@@ -88,7 +214,7 @@ func (p *Plural) Eval(ctx Context) (string, error) {
 	if np.t == 0 {
 		c, ok := p.EqCases[np.i]
 		if ok {
-			return c.Eval(ctx)
+			return EvalEnv(c, ctx, env)
 		}
 	}
 
@@ -112,12 +238,12 @@ func (p *Plural) Eval(ctx Context) (string, error) {
 
 	c, ok := p.Cases[form]
 	if ok {
-		return c.Eval(ctx)
+		return EvalEnv(c, ctx, env)
 	}
 
 	c, ok = p.Cases[plural.Other]
 	if ok {
-		return c.Eval(ctx)
+		return EvalEnv(c, ctx, env)
 	}
 
 	return "", ErrNoDefaultCase
@@ -209,4 +335,7 @@ func parseString(str string) (pm, error) {
 	}, nil
 }
 
-var _ Evalable = (*Plural)(nil)
+var (
+	_ Evalable    = (*Plural)(nil)
+	_ envEvalable = (*Plural)(nil)
+)
@@ -338,3 +338,24 @@ func TestParseString(t *testing.T) {
 		})
 	}
 }
+
+func TestPluralCategories(t *testing.T) {
+	tests := []struct {
+		lang    language.Tag
+		want    []string
+		ordinal []string
+	}{
+		{language.English, []string{"one", "other"}, []string{"one", "two", "few", "other"}},
+		{language.Russian, []string{"one", "few", "many", "other"}, []string{"other"}},
+		{language.Arabic, []string{"zero", "one", "two", "few", "many", "other"}, []string{"other"}},
+		{language.Japanese, []string{"other"}, []string{"other"}},
+		{language.French, []string{"one", "other"}, []string{"one", "other"}},
+		{language.Polish, []string{"one", "few", "many", "other"}, []string{"other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, PluralCategories(tt.lang))
+			assert.Equal(t, tt.ordinal, OrdinalCategories(tt.lang))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package message
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+)
 
 // { GENDER, select,
 //
@@ -13,25 +16,67 @@ const DefaultCase = "other"
 type Select struct {
 	ArgName string
 	Cases   map[string]Evalable
+
+	// Strict reports a missing argument, or an argument of a type
+	// that is not a select key, as an error instead of silently
+	// falling back to the 'other' case.
+	Strict bool
+
+	// star is the default case of MessageFormat 2 selectors,
+	// their 'other' key is matched like any other.
+	star Evalable
+	// integer rounds the argument before matching, like MF2 :integer.
+	integer bool
 }
 
 var ErrNoDefaultCase = errors.New("no default case")
 
 func (s *Select) Eval(ctx Context) (string, error) {
-	v, err := ctx.String(s.ArgName)
-	if err == nil {
-		c, ok := s.Cases[v]
-		if ok {
-			return c.Eval(ctx)
+	return s.evalEnv(ctx, Env{})
+}
+
+func (s *Select) evalEnv(ctx Context, env Env) (string, error) {
+	if _, ok := ctx[s.ArgName]; !ok && !s.Strict {
+		return s.evalDefault(ctx, env)
+	}
+
+	v, err := ctx.SelectKey(s.ArgName)
+	if s.integer {
+		var i int64
+		i, err = roundedInt64(ctx, s.ArgName)
+		v = strconv.FormatInt(i, 10)
+	}
+
+	if err != nil {
+		if !s.Strict {
+			return s.evalDefault(ctx, env)
 		}
+
+		return "", err
+	}
+
+	c, ok := s.Cases[v]
+	if ok {
+		return EvalEnv(c, ctx, env)
+	}
+
+	return s.evalDefault(ctx, env)
+}
+
+func (s *Select) evalDefault(ctx Context, env Env) (string, error) {
+	if s.star != nil {
+		return EvalEnv(s.star, ctx, env)
 	}
 
 	c, ok := s.Cases[DefaultCase]
 	if ok {
-		return c.Eval(ctx)
+		return EvalEnv(c, ctx, env)
 	}
 
 	return "", ErrNoDefaultCase
 }
 
-var _ Evalable = (*Select)(nil)
+var (
+	_ Evalable    = (*Select)(nil)
+	_ envEvalable = (*Select)(nil)
+)
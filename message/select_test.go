@@ -2,11 +2,26 @@ package message
 
 import "testing"
 
+type testColor int
+
+func (c testColor) String() string {
+	return [...]string{"red", "blue"}[c]
+}
+
+type testShade struct {
+	name string
+}
+
+func (s *testShade) String() string {
+	return s.name
+}
+
 func TestSelect_Eval(t *testing.T) {
 	tests := []struct {
 		name    string
 		ArgName string
 		Cases   map[string]Evalable
+		Strict  bool
 		ctx     Context
 		want    string
 		wantErr bool
@@ -19,6 +34,7 @@ func TestSelect_Eval(t *testing.T) {
 				"blue":  &Message{fragments: []Evalable{PlainArg("tone"), Content(" blue")}},
 				"other": Content("color not exists"),
 			},
+			false,
 			Context{"color": "red"},
 			"color is red",
 			false,
@@ -31,6 +47,7 @@ func TestSelect_Eval(t *testing.T) {
 				"blue":  &Message{fragments: []Evalable{PlainArg("tone"), Content(" blue")}},
 				"other": Content("color not exists"),
 			},
+			false,
 			Context{"color": "blue", "tone": "deep"},
 			"deep blue",
 			false,
@@ -43,6 +60,7 @@ func TestSelect_Eval(t *testing.T) {
 				"blue":  &Message{fragments: []Evalable{PlainArg("tone"), Content(" blue")}},
 				"other": Content("color not exists"),
 			},
+			false,
 			Context{"color": "nope", "tone": "deep"},
 			"color not exists",
 			false,
@@ -55,6 +73,7 @@ func TestSelect_Eval(t *testing.T) {
 				"blue":  &Message{fragments: []Evalable{PlainArg("tone"), Content(" blue")}},
 				"other": Content("color not exists"),
 			},
+			false,
 			Context{},
 			"color not exists",
 			false,
@@ -66,16 +85,116 @@ func TestSelect_Eval(t *testing.T) {
 				"red":  Content("color is red"),
 				"blue": &Message{fragments: []Evalable{PlainArg("tone"), Content(" blue")}},
 			},
+			false,
 			Context{"color": "nope", "tone": "deep"},
 			"",
 			true,
 		},
+		{
+			"error if no arg in strict mode",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"other": Content("color not exists"),
+			},
+			true,
+			Context{},
+			"",
+			true,
+		},
+		{
+			"bool",
+			"flag",
+			map[string]Evalable{
+				"true":  Content("yes"),
+				"false": Content("no"),
+				"other": Content("unknown"),
+			},
+			false,
+			Context{"flag": false},
+			"no",
+			false,
+		},
+		{
+			"stringer",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"blue":  Content("color is blue"),
+				"other": Content("color not exists"),
+			},
+			false,
+			Context{"color": testColor(1)},
+			"color is blue",
+			false,
+		},
+		{
+			"number",
+			"num",
+			map[string]Evalable{
+				"1.5":   Content("one and a half"),
+				"other": Content("other"),
+			},
+			false,
+			Context{"num": float32(1.5)},
+			"one and a half",
+			false,
+		},
+		{
+			"default case on unsupported type",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"other": Content("color not exists"),
+			},
+			false,
+			Context{"color": []string{"red"}},
+			"color not exists",
+			false,
+		},
+		{
+			"default case on nil stringer",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"other": Content("color not exists"),
+			},
+			false,
+			Context{"color": (*testShade)(nil)},
+			"color not exists",
+			false,
+		},
+		{
+			"error on nil stringer in strict mode",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"other": Content("color not exists"),
+			},
+			true,
+			Context{"color": (*testShade)(nil)},
+			"",
+			true,
+		},
+		{
+			"error on unsupported type in strict mode",
+			"color",
+			map[string]Evalable{
+				"red":   Content("color is red"),
+				"other": Content("color not exists"),
+			},
+			true,
+			Context{"color": []string{"red"}},
+			"",
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Select{
 				ArgName: tt.ArgName,
 				Cases:   tt.Cases,
+				Strict:  tt.Strict,
 			}
 
 			got, err := s.Eval(tt.ctx)
@@ -0,0 +1,597 @@
+package mf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// AndroidMessageProvider loads messages from strings.xml files of Android resources:
+// values-<lang>/strings.xml, like values-de, values-pt-rBR or values-b+sr+Latn.
+// values/strings.xml is loaded only with MobileDefaultLang option.
+type AndroidMessageProvider struct {
+	dictionaries[*AndroidDictionary]
+}
+
+func NewAndroidMessageProvider(resDir fs.FS, opts ...MobileOption) (*AndroidMessageProvider, error) {
+	provider := AndroidMessageProvider{
+		dictionaries: map[language.Tag]*AndroidDictionary{},
+	}
+
+	o := newMobileOptions(opts)
+	err := fs.WalkDir(resDir, ".", func(p string, f fs.DirEntry, err error) error {
+		if err != nil || f.IsDir() || f.Name() != "strings.xml" {
+			return err
+		}
+
+		lang, ok := androidLang(path.Base(path.Dir(p)), o.defaultLang)
+		if !ok {
+			return nil
+		}
+
+		return provider.loadMessages(resDir, p, lang, opts)
+	})
+
+	return &provider, err
+}
+
+// androidLang returns language of values directory, directories with other qualifiers are skipped.
+func androidLang(dir string, defaultLang language.Tag) (language.Tag, bool) {
+	if dir == "values" {
+		return defaultLang, defaultLang != language.Und
+	}
+
+	qualifiers, ok := strings.CutPrefix(dir, "values-")
+	if !ok {
+		return language.Und, false
+	}
+
+	// values-b+sr+Latn
+	if bcp, ok := strings.CutPrefix(qualifiers, "b+"); ok {
+		tag, err := language.Parse(strings.ReplaceAll(bcp, "+", "-"))
+
+		return tag, err == nil
+	}
+
+	parts := strings.Split(qualifiers, "-")
+	if len(parts) > 2 || len(parts[0]) < 2 || len(parts[0]) > 3 {
+		return language.Und, false
+	}
+
+	// values-pt-rBR
+	if len(parts) == 2 {
+		region, ok := strings.CutPrefix(parts[1], "r")
+		if !ok || len(region) != 2 {
+			return language.Und, false
+		}
+
+		parts[1] = region
+	}
+
+	tag, err := language.Parse(strings.Join(parts, "-"))
+
+	return tag, err == nil
+}
+
+func (p *AndroidMessageProvider) loadMessages(rd fs.FS, file string, lang language.Tag, opts []MobileOption) error {
+	data, err := readFile(rd, file)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(file, lang); err != nil {
+		return err
+	}
+
+	d, err := NewAndroidDictionary(data, opts...)
+	if err != nil {
+		return fileError(file, err)
+	}
+
+	d.file = file
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Description returns the comment before the message.
+func (p *AndroidMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
+}
+
+// AndroidDictionary is a dictionary of Android strings.xml. Strings are messages
+// with printf arguments converted to ICU ones, like %1$s to {arg1}, and plurals
+// are ICU plural expressions of the first argument:
+//
+//	<plurals name="files">
+//	  <item quantity="one">%d file in %2$s</item>
+//	  <item quantity="other">%d files in %2$s</item>
+//	</plurals>
+//
+// is {arg1, plural, one {# file in {arg2}} other {# files in {arg2}}}.
+// Names of arguments and the plural one are taken from MobileArgsFrom messages, if any.
+// String arrays are skipped.
+type AndroidDictionary struct {
+	flatDictionary
+	descriptions map[string]string
+}
+
+// Description returns the comment before the message.
+func (d *AndroidDictionary) Description(id string) string {
+	return d.descriptions[id]
+}
+
+func NewAndroidDictionary(data []byte, opts ...MobileOption) (*AndroidDictionary, error) {
+	d := &AndroidDictionary{
+		flatDictionary: newFlatDictionary(),
+		descriptions:   make(map[string]string),
+	}
+
+	r := &androidReader{dec: xml.NewDecoder(bytes.NewReader(data)), d: d, options: newMobileOptions(opts)}
+	if err := r.read(); err != nil {
+		var (
+			lineErr   *lineError
+			syntaxErr *xml.SyntaxError
+		)
+
+		switch {
+		case errors.As(err, &lineErr):
+			return nil, err
+		case errors.As(err, &syntaxErr):
+			return nil, &lineError{Line: syntaxErr.Line, Msg: syntaxErr.Msg}
+		}
+
+		line, _ := r.dec.InputPos()
+
+		return nil, &lineError{Line: line, Msg: err.Error()}
+	}
+
+	return d, nil
+}
+
+type androidReader struct {
+	dec     *xml.Decoder
+	d       *AndroidDictionary
+	options mobileOptions
+	// comment before the current element
+	comment string
+	// lines of messages to report duplicates
+	line int
+}
+
+func (r *androidReader) errorf(format string, args ...any) error {
+	return &lineError{Line: r.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (r *androidReader) read() error {
+	depth := 0
+	for {
+		t, err := r.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.Comment:
+			r.comment = strings.TrimSpace(string(t))
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Local != "resources" {
+					return errors.Errorf("root element must be <resources>, got <%s>", t.Name.Local)
+				}
+
+				depth++
+
+				continue
+			}
+
+			r.line, _ = r.dec.InputPos()
+			comment := r.comment
+			r.comment = ""
+
+			name := attr(t, "name")
+			var msg string
+			switch t.Name.Local {
+			case "string":
+				msg, err = r.string(name, attr(t, "formatted") != "false")
+			case "plurals":
+				msg, err = r.plurals(name)
+			default:
+				if err := r.dec.Skip(); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := r.add(name, msg, comment); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *androidReader) add(name, msg, comment string) error {
+	if name == "" {
+		return r.errorf("resource without name")
+	}
+
+	if _, ok := r.d.flatMap[name]; ok {
+		return r.errorf("duplicate resource %q, first defined on line %d", name, r.d.lines[name])
+	}
+
+	r.d.flatMap[name] = msg
+	r.d.lines[name] = r.line
+	if comment != "" {
+		r.d.descriptions[name] = comment
+	}
+
+	return nil
+}
+
+func (r *androidReader) string(name string, formatted bool) (string, error) {
+	text, err := r.text()
+	if err != nil {
+		return "", err
+	}
+
+	if !formatted {
+		return parse.JoinSegments([]parse.Segment{{Text: text}}), nil
+	}
+
+	pr := r.options.printfReader(name)
+	segs, err := pr.segments(text, 0, 0, nil)
+	if err != nil {
+		return "", r.errorf("%s", err)
+	}
+
+	return parse.JoinSegments(segs), nil
+}
+
+func (r *androidReader) plurals(name string) (string, error) {
+	pr := r.options.printfReader(name)
+	plural := 1
+	if arg := r.options.pluralArg(name); arg != "" {
+		plural = slices.Index(pr.names, arg) + 1
+	}
+
+	segs := []parse.Segment{{Code: "{" + pr.name(plural) + ", plural,"}}
+	hasOther := false
+	for {
+		t, err := r.dec.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := t.(type) {
+		case xml.EndElement:
+			if !hasOther {
+				return "", r.errorf("plurals %q has no other item", name)
+			}
+
+			return parse.JoinSegments(append(segs, parse.Segment{Code: "}"})), nil
+		case xml.StartElement:
+			if t.Name.Local != "item" {
+				if err := r.dec.Skip(); err != nil {
+					return "", err
+				}
+
+				continue
+			}
+
+			quantity := attr(t, "quantity")
+			hasOther = hasOther || quantity == "other"
+
+			text, err := r.text()
+			if err != nil {
+				return "", err
+			}
+
+			// positions without explicit one start at 1 in every item
+			pr.next = 0
+			item, err := pr.segments(text, plural, 0, nil)
+			if err != nil {
+				return "", r.errorf("%s", err)
+			}
+
+			segs = append(segs, parse.Segment{Code: " " + quantity + " {"})
+			segs = append(segs, item...)
+			segs = append(segs, parse.Segment{Code: "}"})
+		}
+	}
+}
+
+// text reads content of the element with Android escapes, inner markup, like <b>, is kept as text,
+// xliff:g elements are unwrapped.
+func (r *androidReader) text() (string, error) {
+	var u androidUnescaper
+	for depth := 1; ; {
+		t, err := r.dec.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := t.(type) {
+		case xml.CharData:
+			u.write(string(t))
+		case xml.StartElement:
+			depth++
+			if t.Name.Local != "g" {
+				u.markup(xmlStartTag(t))
+			}
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				return u.String(), nil
+			}
+
+			if t.Name.Local != "g" {
+				u.markup("</" + t.Name.Local + ">")
+			}
+		}
+	}
+}
+
+func xmlStartTag(t xml.StartElement) string {
+	var b strings.Builder
+	b.WriteString("<" + t.Name.Local)
+	for _, a := range t.Attr {
+		b.WriteString(" " + a.Name.Local + "=" + xmlAttr(a.Value))
+	}
+
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// androidUnescaper unescapes text like aapt: whitespace is collapsed outside of
+// double quotes, which are removed, and backslash escapes are replaced.
+type androidUnescaper struct {
+	b      strings.Builder
+	quoted bool
+	space  bool
+	escape bool
+	// unicode escape digits
+	unicode string
+	inUni   bool
+}
+
+func (u *androidUnescaper) markup(tag string) {
+	u.flushSpace()
+	u.b.WriteString(tag)
+}
+
+func (u *androidUnescaper) flushSpace() {
+	if u.space && u.b.Len() > 0 {
+		u.b.WriteByte(' ')
+	}
+
+	u.space = false
+}
+
+func (u *androidUnescaper) write(s string) {
+	for _, c := range s {
+		switch {
+		case u.inUni:
+			u.unicode += string(c)
+			if len(u.unicode) == 4 {
+				n, err := strconv.ParseUint(u.unicode, 16, 32)
+				if err == nil {
+					u.b.WriteRune(rune(n))
+				}
+
+				u.inUni, u.unicode = false, ""
+			}
+		case u.escape:
+			u.escape = false
+			u.flushSpace()
+			switch c {
+			case 'n':
+				u.b.WriteByte('\n')
+			case 't':
+				u.b.WriteByte('\t')
+			case 'u':
+				u.inUni = true
+			default:
+				u.b.WriteRune(c)
+			}
+		case c == '\\':
+			u.escape = true
+		case c == '"':
+			u.flushSpace()
+			u.quoted = !u.quoted
+		case !u.quoted && (c == ' ' || c == '\n' || c == '\t' || c == '\r'):
+			u.space = true
+		default:
+			u.flushSpace()
+			u.b.WriteRune(c)
+		}
+	}
+}
+
+func (u *androidUnescaper) String() string {
+	return u.b.String()
+}
+
+// WriteAndroidStrings writes messages of the language as Android strings.xml.
+// Arguments become printf arguments, {name} is %1$s, {n, number, integer} is %1$d and {n, number} is %1$f.
+// A message with one plural expression becomes plurals, text around the
+// expression is added to every item, # is the plural argument:
+//
+//	{n, plural, one {# file} other {# files}} in {dir}
+//
+// is %1$d file in %2$s and %1$d files in %2$s items. Select, nested plurals, exact
+// plural cases and other functions are not supported, see MobileSkipUnsupported.
+// Message ids must be valid resource names, descriptions are written as comments.
+func WriteAndroidStrings(w io.Writer, p ListableProvider, lang language.Tag, opts ...MobileOption) error {
+	o := newMobileOptions(opts)
+	descriptions, _ := p.(DescriptionProvider)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+	for id := range p.IDs(lang) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := androidResource(id, msg, &o)
+		if o.skip(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if descriptions != nil {
+			if desc := descriptions.Description(lang, id); desc != "" {
+				fmt.Fprintf(bw, "    <!-- %s -->\n", strings.ReplaceAll(desc, "--", "- -"))
+			}
+		}
+
+		bw.WriteString(res)
+	}
+
+	bw.WriteString("</resources>\n")
+
+	return bw.Flush()
+}
+
+// androidResource returns string or plurals element of the message.
+func androidResource(id, msg string, o *mobileOptions) (string, error) {
+	if !isAndroidName(id) {
+		return "", &unsupportedError{ID: id, Reason: "invalid resource name"}
+	}
+
+	m, err := parse.Parse(msg)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to parse %s", id)
+	}
+
+	pw := newPrintfWriter(id, m, o, "s", "f", androidEscape)
+
+	// the plural expression and fragments around it
+	plural := -1
+	for i, f := range m.Fragments {
+		if f.Expr == nil {
+			continue
+		}
+
+		if plural >= 0 {
+			return "", pw.unsupported("several plural expressions are not supported")
+		}
+
+		plural = i
+	}
+
+	if plural < 0 {
+		var b strings.Builder
+		if err := pw.write(&b, m.Fragments, ""); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("    <string name=%s>%s</string>\n", xmlAttr(id), androidSpaces(b.String())), nil
+	}
+
+	e := m.Fragments[plural].Expr
+	cases, keys, err := pw.pluralCases(e, false)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "    <plurals name=%s>\n", xmlAttr(id))
+	for _, key := range keys {
+		fragments := append(append(append([]*parse.Fragment{}, m.Fragments[:plural]...), cases[key].Fragments...), m.Fragments[plural+1:]...)
+
+		var item strings.Builder
+		if err := pw.write(&item, fragments, e.Name); err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "        <item quantity=%q>%s</item>\n", key, androidSpaces(item.String()))
+	}
+
+	b.WriteString("    </plurals>\n")
+
+	return b.String(), nil
+}
+
+func isAndroidName(id string) bool {
+	for i := range len(id) {
+		c := id[i]
+		if c != '_' && c != '.' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return id != ""
+}
+
+var androidReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// androidEscape escapes text for strings.xml.
+func androidEscape(s string) string {
+	return androidReplacer.Replace(s)
+}
+
+// androidSpaces escapes spaces, which aapt collapses, and @ or ? at the start of the string.
+func androidSpaces(s string) string {
+	if strings.HasPrefix(s, "@") || strings.HasPrefix(s, "?") {
+		s = `\` + s
+	}
+
+	var b strings.Builder
+	for i := range len(s) {
+		if s[i] == ' ' && (i == 0 || i == len(s)-1 || s[i-1] == ' ') {
+			b.WriteString(`\u0020`)
+
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+var (
+	_ ListableProvider    = (*AndroidMessageProvider)(nil)
+	_ SourceProvider      = (*AndroidMessageProvider)(nil)
+	_ DescriptionProvider = (*AndroidMessageProvider)(nil)
+)
@@ -0,0 +1,195 @@
+package mf
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+const testAndroidStrings = `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <!-- Page title -->
+    <string name="title">Shop</string>
+    <string name="hello">Hello, <xliff:g id="name">%1$s</xliff:g>! It\'s   "  50%"  off: %2$d%%</string>
+    <string name="markup">Tap <b>{here}</b>\né</string>
+    <string name="raw" formatted="false">100% {sure}</string>
+    <string-array name="sizes">
+        <item>S</item>
+    </string-array>
+    <plurals name="cart.items">
+        <item quantity="one">%d item in %2$s</item>
+        <item quantity="other">%1$d items in %2$s</item>
+    </plurals>
+</resources>
+`
+
+func TestNewAndroidDictionary(t *testing.T) {
+	d, err := NewAndroidDictionary([]byte(testAndroidStrings))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cart.items", "hello", "markup", "raw", "title"}, slices.Collect(d.IDs()))
+
+	for id, want := range map[string]string{
+		"title":      "Shop",
+		"hello":      "Hello, {arg1}! It's   50% off: {arg2, number, integer}%",
+		"markup":     "Tap <b>'{here}'</b>\né",
+		"raw":        "100% '{sure}'",
+		"cart.items": "{arg1, plural, one {# item in {arg2}} other {# items in {arg2}}}",
+	} {
+		msg, err := d.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, msg, id)
+	}
+
+	assert.Equal(t, "Page title", d.Description("title"))
+	assert.Equal(t, 4, d.Line("title"))
+
+	ref, err := NewYamlMessageProvider(fstest.MapFS{"messages.en.yaml": {Data: []byte(`
+hello: "Hello, {name}! {discount, number} off"
+cart:
+  items: "{count, plural, one {# item in {shop}} other {# items in {shop}}}"
+`)}})
+	require.NoError(t, err)
+
+	d, err = NewAndroidDictionary([]byte(testAndroidStrings), MobileArgsFrom(ref, language.English))
+	require.NoError(t, err)
+
+	msg, err := d.Get("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, {name}! It's   50% off: {discount, number, integer}%", msg)
+
+	msg, err = d.Get("cart.items")
+	require.NoError(t, err)
+	assert.Equal(t, "{count, plural, one {# item in {shop}} other {# items in {shop}}}", msg)
+}
+
+func TestNewAndroidDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"root", `<strings/>`, "line 1: root element must be <resources>, got <strings>"},
+		{"duplicate", "<resources>\n<string name=\"a\">A</string>\n<string name=\"a\">B</string>\n</resources>", `line 3: duplicate resource "a", first defined on line 2`},
+		{"no name", `<resources><string>A</string></resources>`, "line 1: resource without name"},
+		{"no other", `<resources><plurals name="p"><item quantity="one">A</item></plurals></resources>`, `line 1: plurals "p" has no other item`},
+		{"format", `<resources><string name="a">%x</string></resources>`, "line 1: format %x is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAndroidDictionary([]byte(tt.in))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewAndroidMessageProvider(t *testing.T) {
+	p, err := NewAndroidMessageProvider(fstest.MapFS{
+		"values/strings.xml":           {Data: []byte(testAndroidStrings)},
+		"values-de/strings.xml":        {Data: []byte(`<resources><string name="title">Laden</string></resources>`)},
+		"values-pt-rBR/strings.xml":    {Data: []byte(`<resources><string name="title">Loja</string></resources>`)},
+		"values-b+sr+Latn/strings.xml": {Data: []byte(`<resources><string name="title">Prodavnica</string></resources>`)},
+		"values-night/strings.xml":     {Data: []byte(`<invalid`)},
+		"values-de-land/strings.xml":   {Data: []byte(`<invalid`)},
+		"values-de/strings_extra.xml":  {Data: []byte(`<invalid`)},
+		"values-fr/nested/strings.xml": {Data: []byte(`<invalid`)},
+		"drawable-de/strings.xml":      {Data: []byte(`<invalid`)},
+	}, MobileDefaultLang(language.English))
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.German, language.English, language.MustParse("pt-BR"), language.MustParse("sr-Latn")}, p.Languages())
+	assert.Equal(t, "Page title", p.Description(language.English, "title"))
+
+	file, line, ok := p.Source(language.English, "hello")
+	assert.True(t, ok)
+	assert.Equal(t, "values/strings.xml", file)
+	assert.Equal(t, 5, line)
+
+	p, err = NewAndroidMessageProvider(fstest.MapFS{"values/strings.xml": {Data: []byte(testAndroidStrings)}})
+	require.NoError(t, err)
+	assert.Empty(t, p.Languages(), "no default language")
+
+	_, err = NewAndroidMessageProvider(fstest.MapFS{"values-de/strings.xml": {Data: []byte("<resources>\n<string>")}})
+	require.ErrorContains(t, err, "values-de/strings.xml:2: ")
+}
+
+func TestWriteAndroidStrings(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+# Page title
+title: "@Shop"
+hello: "Hello, {name}! It's 50% {discount, number, integer}"
+spaces: " a  b "
+cart:
+  items: "{count, plural, one {# item} other {# items}} in {shop}"
+select: "{g, select, other {x}}"
+`)},
+		"messages.de.yaml": {Data: []byte(`
+cart:
+  items: "{shop}: {count, plural, one {# Artikel} other {# Artikel}}"
+`)},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.EqualError(t, WriteAndroidStrings(&buf, p, language.English), "select: {g, select} is not supported")
+
+	buf.Reset()
+	require.NoError(t, WriteAndroidStrings(&buf, p, language.English, MobileSkipUnsupported()))
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <plurals name="cart.items">
+        <item quantity="one">%1$d item in %2$s</item>
+        <item quantity="other">%1$d items in %2$s</item>
+    </plurals>
+    <string name="hello">Hello, %1$s! It\'s 50%% %2$d</string>
+    <string name="spaces">\u0020a \u0020b\u0020</string>
+    <!-- Page title -->
+    <string name="title">\@Shop</string>
+</resources>
+`, buf.String())
+
+	en, err := NewAndroidDictionary(buf.Bytes())
+	require.NoError(t, err)
+	msg, err := en.Get("spaces")
+	require.NoError(t, err)
+	assert.Equal(t, " a  b ", msg)
+
+	buf.Reset()
+	require.NoError(t, WriteAndroidStrings(&buf, p, language.German, MobileArgsFrom(p, language.English)))
+	assert.Contains(t, buf.String(), `<item quantity="one">%2$s: %1$d Artikel</item>`)
+
+	de, err := NewAndroidDictionary(buf.Bytes(), MobileArgsFrom(p, language.English))
+	require.NoError(t, err)
+	msg, err = de.Get("cart.items")
+	require.NoError(t, err)
+	assert.Equal(t, "{count, plural, one {{shop}: # Artikel} other {{shop}: # Artikel}}", msg)
+}
+
+func TestWriteAndroidStrings_Decimal(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{"messages.en.yaml": {Data: []byte(`
+rate: "Rate {rate, number} of {n, number, integer}"
+`)}})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAndroidStrings(&buf, p, language.English))
+	assert.Contains(t, buf.String(), `<string name="rate">Rate %1$f of %2$d</string>`)
+
+	android, err := NewAndroidMessageProvider(fstest.MapFS{"values-en/strings.xml": {Data: buf.Bytes()}}, MobileArgsFrom(p, language.English))
+	require.NoError(t, err)
+
+	msg, err := android.Get(language.English, "rate")
+	require.NoError(t, err)
+	assert.Equal(t, "Rate {rate, number} of {n, number, integer}", msg)
+
+	b, err := NewBundle(WithProvider(android))
+	require.NoError(t, err)
+	assert.Equal(t, "Rate 1.5 of 2", b.Translator("en").Trans("rate", Arg("rate", 1.5), Arg("n", 2)))
+}
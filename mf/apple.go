@@ -0,0 +1,811 @@
+package mf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// AppleMessageProvider loads messages from Localizable.strings and Localizable.stringsdict
+// files of <lang>.lproj directories, like en.lproj or pt-BR.lproj. Base.lproj is skipped.
+type AppleMessageProvider struct {
+	dictionaries[*AppleDictionary]
+}
+
+func NewAppleMessageProvider(dir fs.FS, opts ...MobileOption) (*AppleMessageProvider, error) {
+	provider := AppleMessageProvider{
+		dictionaries: map[language.Tag]*AppleDictionary{},
+	}
+
+	// strings and stringsdict files by language directory
+	type files struct {
+		lang                 language.Tag
+		strings, stringsdict string
+	}
+
+	var langs []*files
+	byDir := map[string]*files{}
+	err := fs.WalkDir(dir, ".", func(p string, f fs.DirEntry, err error) error {
+		if err != nil || f.IsDir() || f.Name() != "Localizable.strings" && f.Name() != "Localizable.stringsdict" {
+			return err
+		}
+
+		lproj := path.Dir(p)
+		name, ok := strings.CutSuffix(path.Base(lproj), ".lproj")
+		if !ok || name == "Base" {
+			return nil
+		}
+
+		lf, ok := byDir[lproj]
+		if !ok {
+			lang, err := language.Parse(name)
+			if err != nil {
+				return errors.Wrapf(err, "unable to parse language of %s", lproj)
+			}
+
+			lf = &files{lang: lang}
+			byDir[lproj] = lf
+			langs = append(langs, lf)
+		}
+
+		if path.Ext(p) == ".strings" {
+			lf.strings = p
+		} else {
+			lf.stringsdict = p
+		}
+
+		return nil
+	})
+	if err != nil {
+		return &provider, err
+	}
+
+	for _, lf := range langs {
+		if err := provider.loadMessages(dir, lf.strings, lf.stringsdict, lf.lang, opts); err != nil {
+			return &provider, err
+		}
+	}
+
+	return &provider, nil
+}
+
+func (p *AppleMessageProvider) loadMessages(rd fs.FS, stringsFile, stringsdictFile string, lang language.Tag, opts []MobileOption) error {
+	if err := p.checkLang(path.Dir(stringsFile+stringsdictFile), lang); err != nil {
+		return err
+	}
+
+	d := newAppleDictionary()
+	if stringsFile != "" {
+		data, err := readFile(rd, stringsFile)
+		if err != nil {
+			return err
+		}
+
+		if err := d.loadStrings(data, opts); err != nil {
+			return fileError(stringsFile, err)
+		}
+
+		d.file = stringsFile
+	}
+
+	if stringsdictFile != "" {
+		data, err := readFile(rd, stringsdictFile)
+		if err != nil {
+			return err
+		}
+
+		if err := d.loadStringsdict(data, opts); err != nil {
+			return fileError(stringsdictFile, err)
+		}
+
+		d.stringsdictFile = stringsdictFile
+	}
+
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Description returns the comment before the message in .strings file.
+func (p *AppleMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
+}
+
+// Source returns file and line of the message, plurals are in the stringsdict file.
+func (p *AppleMessageProvider) Source(lang language.Tag, id string) (string, int, bool) {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return "", 0, false
+	}
+
+	line := d.Line(id)
+	if d.plurals[id] {
+		return d.stringsdictFile, line, true
+	}
+
+	return d.file, line, line > 0
+}
+
+// AppleDictionary is a dictionary of iOS .strings and .stringsdict files:
+//
+//	/* Greeting */
+//	"hello" = "Hello, %@!";
+//
+// Strings are messages with printf arguments converted to ICU ones, like %@ to {arg1}.
+// Plural variables of stringsdict are ICU plural expressions,
+// named after variables, like {files, plural, ...} for %#@files@, and zero rule is =0.
+// Names of arguments are taken from MobileArgsFrom messages, if any.
+// Messages of stringsdict take precedence over strings ones, like on iOS.
+type AppleDictionary struct {
+	flatDictionary
+	descriptions map[string]string
+	// plurals are messages of stringsdict
+	plurals         map[string]bool
+	stringsdictFile string
+}
+
+func newAppleDictionary() *AppleDictionary {
+	return &AppleDictionary{
+		flatDictionary: newFlatDictionary(),
+		descriptions:   make(map[string]string),
+		plurals:        make(map[string]bool),
+	}
+}
+
+// NewAppleDictionary loads .strings and .stringsdict files, any of them could be nil.
+func NewAppleDictionary(stringsData, stringsdictData []byte, opts ...MobileOption) (*AppleDictionary, error) {
+	d := newAppleDictionary()
+	if err := d.loadStrings(stringsData, opts); err != nil {
+		return nil, err
+	}
+
+	if err := d.loadStringsdict(stringsdictData, opts); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Description returns the comment before the message in .strings file.
+func (d *AppleDictionary) Description(id string) string {
+	return d.descriptions[id]
+}
+
+func (d *AppleDictionary) loadStrings(data []byte, opts []MobileOption) error {
+	text, err := decodeStrings(data)
+	if err != nil {
+		return err
+	}
+
+	p := &stringsParser{s: text, line: 1}
+	o := newMobileOptions(opts)
+	for {
+		key, value, comment, line, err := p.entry()
+		if err != nil {
+			return err
+		}
+
+		if line == 0 {
+			return nil
+		}
+
+		if first, ok := d.lines[key]; ok {
+			return &lineError{Line: line, Msg: fmt.Sprintf("duplicate key %q, first defined on line %d", key, first)}
+		}
+
+		pr := o.printfReader(key)
+		segs, err := pr.segments(value, 0, 0, nil)
+		if err != nil {
+			return &lineError{Line: line, Msg: err.Error()}
+		}
+
+		d.flatMap[key] = parse.JoinSegments(segs)
+		d.lines[key] = line
+		if comment != "" {
+			d.descriptions[key] = comment
+		}
+	}
+}
+
+// decodeStrings decodes UTF-16 files with BOM, Xcode used to write them.
+func decodeStrings(data []byte) (string, error) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = binary.BigEndian
+	default:
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(data) {
+			return "", errors.New("strings file must be UTF-8 or UTF-16 with BOM")
+		}
+
+		return string(data), nil
+	}
+
+	data = data[2:]
+	if len(data)%2 != 0 {
+		return "", errors.New("invalid UTF-16 strings file")
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+// stringsParser parses "key" = "value"; entries of .strings files.
+type stringsParser struct {
+	s    string
+	line int
+	// comment is the last comment before the entry
+	comment string
+}
+
+func (p *stringsParser) errorf(format string, args ...any) error {
+	return &lineError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// skip skips whitespace and comments.
+func (p *stringsParser) skip() error {
+	for len(p.s) > 0 {
+		switch {
+		case p.s[0] == '\n':
+			p.line++
+			p.s = p.s[1:]
+		case p.s[0] == ' ' || p.s[0] == '\t' || p.s[0] == '\r':
+			p.s = p.s[1:]
+		case strings.HasPrefix(p.s, "//"):
+			end := strings.IndexByte(p.s, '\n')
+			if end < 0 {
+				end = len(p.s)
+			}
+
+			p.comment = strings.TrimSpace(p.s[2:end])
+			p.s = p.s[end:]
+		case strings.HasPrefix(p.s, "/*"):
+			end := strings.Index(p.s, "*/")
+			if end < 0 {
+				return p.errorf("unclosed comment")
+			}
+
+			p.comment = strings.TrimSpace(p.s[2:end])
+			p.line += strings.Count(p.s[:end], "\n")
+			p.s = p.s[end+2:]
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// entry returns the next entry, line is 0 at the end of file.
+func (p *stringsParser) entry() (key, value, comment string, line int, err error) {
+	p.comment = ""
+	if err := p.skip(); err != nil {
+		return "", "", "", 0, err
+	}
+
+	if p.s == "" {
+		return "", "", "", 0, nil
+	}
+
+	comment, line = p.comment, p.line
+	if key, err = p.string(); err != nil {
+		return "", "", "", 0, err
+	}
+
+	if err := p.expect('='); err != nil {
+		return "", "", "", 0, err
+	}
+
+	if value, err = p.string(); err != nil {
+		return "", "", "", 0, err
+	}
+
+	if err := p.expect(';'); err != nil {
+		return "", "", "", 0, err
+	}
+
+	return key, value, comment, line, nil
+}
+
+func (p *stringsParser) expect(c byte) error {
+	if err := p.skip(); err != nil {
+		return err
+	}
+
+	if p.s == "" || p.s[0] != c {
+		return p.errorf("expected %q", c)
+	}
+
+	p.s = p.s[1:]
+
+	return nil
+}
+
+// string parses quoted string with escapes, or unquoted word.
+func (p *stringsParser) string() (string, error) {
+	if err := p.skip(); err != nil {
+		return "", err
+	}
+
+	if p.s == "" || p.s[0] != '"' {
+		n := span(p.s, func(c byte) bool {
+			return c == '_' || c == '.' || c == '-' || c == '$' || c == '/' || c == ':' || isDigitByte(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		})
+		if n == 0 {
+			return "", p.errorf("expected string")
+		}
+
+		word := p.s[:n]
+		p.s = p.s[n:]
+
+		return word, nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(p.s); i++ {
+		c := p.s[i]
+		switch c {
+		case '"':
+			p.s = p.s[i+1:]
+
+			return b.String(), nil
+		case '\n':
+			p.line++
+		case '\\':
+			i++
+			if i >= len(p.s) {
+				return "", p.errorf("unclosed string")
+			}
+
+			switch e := p.s[i]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'U', 'u':
+				if i+4 >= len(p.s) {
+					return "", p.errorf("invalid escape \\%c", e)
+				}
+
+				r, err := strconv.ParseUint(p.s[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid escape \\%c%s", e, p.s[i+1:i+5])
+				}
+
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				b.WriteByte(e)
+			}
+
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return "", p.errorf("unclosed string")
+}
+
+func (d *AppleDictionary) loadStringsdict(data []byte, opts []MobileOption) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	root, err := readPlist(dec)
+	if err != nil {
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return &lineError{Line: syntaxErr.Line, Msg: syntaxErr.Msg}
+		}
+
+		line, _ := dec.InputPos()
+
+		return &lineError{Line: line, Msg: err.Error()}
+	}
+
+	o := newMobileOptions(opts)
+	for _, e := range root {
+		entry, ok := e.Value.(plistDict)
+		if !ok {
+			return &lineError{Line: e.Line, Msg: fmt.Sprintf("%s must be a dict", e.Key)}
+		}
+
+		msg, err := stringsdictMessage(e.Key, entry, &o)
+		if err != nil {
+			return &lineError{Line: e.Line, Msg: fmt.Sprintf("%s: %s", e.Key, err)}
+		}
+
+		d.flatMap[e.Key] = msg
+		d.lines[e.Key] = e.Line
+		d.plurals[e.Key] = true
+	}
+
+	return nil
+}
+
+// stringsdictMessage converts format of the entry with its plural variables.
+func stringsdictMessage(id string, entry plistDict, o *mobileOptions) (string, error) {
+	format, ok := entry.string("NSStringLocalizedFormatKey")
+	if !ok {
+		return "", errors.New("no NSStringLocalizedFormatKey string")
+	}
+
+	pr := o.printfReader(id)
+
+	var variable func(name string, pos int) ([]parse.Segment, error)
+	variable = func(name string, pos int) ([]parse.Segment, error) {
+		rules, ok := entry.dict(name)
+		if !ok {
+			return nil, errors.Errorf("no dict of variable %s", name)
+		}
+
+		if spec, _ := rules.string("NSStringFormatSpecTypeKey"); spec != "NSStringPluralRuleType" {
+			return nil, errors.Errorf("variable %s: %s is not supported", name, spec)
+		}
+
+		segs := []parse.Segment{{Code: "{" + pr.name(pos) + ", plural,"}}
+		hasOther := false
+		for _, rule := range rules {
+			key := rule.Key
+			if key == "NSStringFormatSpecTypeKey" || key == "NSStringFormatValueTypeKey" {
+				continue
+			}
+
+			text, ok := rule.Value.(string)
+			if !ok || !slices.Contains(pluralCategories, key) {
+				return nil, errors.Errorf("variable %s: unexpected rule %s", name, key)
+			}
+
+			hasOther = hasOther || key == "other"
+			if key == "zero" {
+				// zero rule of iOS is used for 0 in all languages
+				key = "=0"
+			}
+
+			// rules could have variables too, specifiers without position are the variable
+			rs, err := pr.segments(text, pos, pos, variable)
+			if err != nil {
+				return nil, err
+			}
+
+			segs = append(segs, parse.Segment{Code: " " + key + " {"})
+			segs = append(segs, rs...)
+			segs = append(segs, parse.Segment{Code: "}"})
+		}
+
+		if !hasOther {
+			return nil, errors.Errorf("variable %s has no other rule", name)
+		}
+
+		return append(segs, parse.Segment{Code: "}"}), nil
+	}
+
+	segs, err := pr.segments(format, 0, 0, variable)
+	if err != nil {
+		return "", err
+	}
+
+	return parse.JoinSegments(segs), nil
+}
+
+// plistDict is a dict of property list with keys in order,
+// values are strings or plistDict, other values are nil.
+type plistDict []plistEntry
+
+type plistEntry struct {
+	Key   string
+	Value any
+	Line  int
+}
+
+func (d plistDict) string(key string) (string, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			s, ok := e.Value.(string)
+
+			return s, ok
+		}
+	}
+
+	return "", false
+}
+
+func (d plistDict) dict(key string) (plistDict, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			v, ok := e.Value.(plistDict)
+
+			return v, ok
+		}
+	}
+
+	return nil, false
+}
+
+// readPlist reads the root dict of XML property list.
+func readPlist(dec *xml.Decoder) (plistDict, error) {
+	for {
+		t, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no root dict")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if start, ok := t.(xml.StartElement); ok && start.Name.Local == "dict" {
+			return readPlistDict(dec)
+		}
+	}
+}
+
+func readPlistDict(dec *xml.Decoder) (plistDict, error) {
+	var (
+		d   plistDict
+		key *plistEntry
+	)
+
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.EndElement:
+			return d, nil
+		case xml.StartElement:
+			line, _ := dec.InputPos()
+
+			if t.Name.Local == "key" {
+				var k string
+				if err := dec.DecodeElement(&k, &t); err != nil {
+					return nil, err
+				}
+
+				key = &plistEntry{Key: k, Line: line}
+
+				continue
+			}
+
+			if key == nil {
+				return nil, errors.Errorf("<%s> without key", t.Name.Local)
+			}
+
+			switch t.Name.Local {
+			case "string":
+				var s string
+				if err := dec.DecodeElement(&s, &t); err != nil {
+					return nil, err
+				}
+
+				key.Value = s
+			case "dict":
+				if key.Value, err = readPlistDict(dec); err != nil {
+					return nil, err
+				}
+			default:
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+
+			d = append(d, *key)
+			key = nil
+		}
+	}
+}
+
+// WriteAppleStrings writes messages without plurals as iOS .strings file,
+// plurals are written by WriteAppleStringsdict. Arguments become printf arguments,
+// {name} and {n, number} are %1$@, {n, number, integer} is %1$d. Select, plurals and other functions
+// are not supported, see MobileSkipUnsupported. Descriptions are written as comments.
+func WriteAppleStrings(w io.Writer, p ListableProvider, lang language.Tag, opts ...MobileOption) error {
+	o := newMobileOptions(opts)
+	descriptions, _ := p.(DescriptionProvider)
+
+	bw := bufio.NewWriter(w)
+	for id := range p.IDs(lang) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		m, err := parse.Parse(msg)
+		if err != nil {
+			return errors.Wrapf(err, "unable to parse %s", id)
+		}
+
+		if hasPluralExpr(m) {
+			continue
+		}
+
+		var b strings.Builder
+		err = writeAppleFormat(&b, newPrintfWriter(id, m, &o, "@", "@", appleEscape), m.Fragments)
+		if o.skip(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if descriptions != nil {
+			if desc := descriptions.Description(lang, id); desc != "" {
+				fmt.Fprintf(bw, "/* %s */\n", strings.ReplaceAll(desc, "*/", "* /"))
+			}
+		}
+
+		fmt.Fprintf(bw, "\"%s\" = \"%s\";\n\n", appleEscape(id), b.String())
+	}
+
+	return bw.Flush()
+}
+
+// WriteAppleStringsdict writes messages with top level plurals as iOS .stringsdict file.
+// Every plural is a variable named after its argument, text around them is the format:
+//
+//	{count, plural, =0 {No files} one {# file} other {# files}} in {dir}
+//
+// is %1$#@count@ in %2$@ format with zero, one and other rules of count variable.
+// Exact plural cases except =0, nested plurals and select are not supported, see MobileSkipUnsupported.
+func WriteAppleStringsdict(w io.Writer, p ListableProvider, lang language.Tag, opts ...MobileOption) error {
+	o := newMobileOptions(opts)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+
+	for id := range p.IDs(lang) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		m, err := parse.Parse(msg)
+		if err != nil {
+			return errors.Wrapf(err, "unable to parse %s", id)
+		}
+
+		if !hasPluralExpr(m) {
+			continue
+		}
+
+		entry, err := stringsdictEntry(id, m, &o)
+		if o.skip(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		bw.WriteString(entry)
+	}
+
+	bw.WriteString("</dict>\n</plist>\n")
+
+	return bw.Flush()
+}
+
+func hasPluralExpr(m *parse.Message) bool {
+	for _, f := range m.Fragments {
+		if f.Expr != nil && f.Expr.Func == "plural" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeAppleFormat writes fragments outside of plurals, top level select is not supported.
+func writeAppleFormat(b *strings.Builder, pw *printfWriter, fragments []*parse.Fragment) error {
+	for _, f := range fragments {
+		if f.Expr != nil {
+			return pw.unsupported("{%s, %s} is not supported", f.Expr.Name, f.Expr.Func)
+		}
+	}
+
+	return pw.write(b, fragments, "")
+}
+
+func stringsdictEntry(id string, m *parse.Message, o *mobileOptions) (string, error) {
+	pw := newPrintfWriter(id, m, o, "@", "@", xmlText)
+
+	var (
+		format strings.Builder
+		vars   strings.Builder
+	)
+
+	for _, f := range m.Fragments {
+		if f.Expr == nil || f.Expr.Func != "plural" {
+			if err := writeAppleFormat(&format, pw, []*parse.Fragment{f}); err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		cases, keys, err := pw.pluralCases(f.Expr, true)
+		if err != nil {
+			return "", err
+		}
+
+		spec := pw.spec(f.Expr.Name, "")
+		format.WriteString(spec + "#@" + f.Expr.Name + "@")
+
+		fmt.Fprintf(&vars, "\t\t<key>%s</key>\n\t\t<dict>\n", f.Expr.Name)
+		vars.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n\t\t\t<string>NSStringPluralRuleType</string>\n")
+		vars.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>d</string>\n")
+		for _, key := range keys {
+			var rule strings.Builder
+			if err := pw.write(&rule, cases[key].Fragments, f.Expr.Name); err != nil {
+				return "", err
+			}
+
+			fmt.Fprintf(&vars, "\t\t\t<key>%s</key>\n\t\t\t<string>%s</string>\n", key, rule.String())
+		}
+
+		vars.WriteString("\t\t</dict>\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t<key>%s</key>\n\t<dict>\n", xmlText(id))
+	fmt.Fprintf(&b, "\t\t<key>NSStringLocalizedFormatKey</key>\n\t\t<string>%s</string>\n", format.String())
+	b.WriteString(vars.String())
+	b.WriteString("\t</dict>\n")
+
+	return b.String(), nil
+}
+
+var appleReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+)
+
+// appleEscape escapes text for .strings files.
+func appleEscape(s string) string {
+	return appleReplacer.Replace(s)
+}
+
+var (
+	_ ListableProvider    = (*AppleMessageProvider)(nil)
+	_ SourceProvider      = (*AppleMessageProvider)(nil)
+	_ DescriptionProvider = (*AppleMessageProvider)(nil)
+)
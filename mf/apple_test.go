@@ -0,0 +1,240 @@
+package mf
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+const testAppleStrings = `/* Page title */
+"title" = "Shop";
+// Greeting
+"hello" = "Hello, %@! It's 50%% off: %2$ld%%";
+raw = "100% {sure}\n\"\U00e9\"";
+"cart.items" = "Overridden by stringsdict";
+`
+
+const testAppleStringsdict = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>cart.items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@items@ in %2$@</string>
+		<key>items</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>zero</key>
+			<string>No items</string>
+			<key>one</key>
+			<string>%d item</string>
+			<key>other</key>
+			<string>%d items</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestNewAppleDictionary(t *testing.T) {
+	d, err := NewAppleDictionary([]byte(testAppleStrings), []byte(testAppleStringsdict))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cart.items", "hello", "raw", "title"}, slices.Collect(d.IDs()))
+
+	for id, want := range map[string]string{
+		"title":      "Shop",
+		"hello":      "Hello, {arg1}! It's 50% off: {arg2, number, integer}%",
+		"raw":        "100% '{sure}'\n\"é\"",
+		"cart.items": "{items, plural, =0 {No items} one {# item} other {# items}} in {arg2}",
+	} {
+		msg, err := d.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, msg, id)
+	}
+
+	assert.Equal(t, "Page title", d.Description("title"))
+	assert.Equal(t, "Greeting", d.Description("hello"))
+	assert.Equal(t, 4, d.Line("hello"))
+	assert.Equal(t, 5, d.Line("cart.items"))
+
+	ref, err := NewYamlMessageProvider(fstest.MapFS{"messages.en.yaml": {Data: []byte(`
+hello: "Hello, {name}! {discount, number} off"
+cart:
+  items: "{count, plural, one {# item} other {# items}} in {shop}"
+`)}})
+	require.NoError(t, err)
+
+	d, err = NewAppleDictionary([]byte(testAppleStrings), []byte(testAppleStringsdict), MobileArgsFrom(ref, language.English))
+	require.NoError(t, err)
+
+	msg, err := d.Get("cart.items")
+	require.NoError(t, err)
+	assert.Equal(t, "{count, plural, =0 {No items} one {# item} other {# items}} in {shop}", msg, "names of reference")
+
+	utf16le := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(`"a" = "ä";`)) {
+		utf16le = append(utf16le, byte(u), byte(u>>8))
+	}
+
+	d, err = NewAppleDictionary(utf16le, nil)
+	require.NoError(t, err)
+	msg, err = d.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "ä", msg)
+}
+
+func TestNewAppleDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		strings     string
+		stringsdict string
+		err         string
+	}{
+		{"duplicate", "\"a\" = \"A\";\n\"a\" = \"B\";", "", `line 2: duplicate key "a", first defined on line 1`},
+		{"no semicolon", `"a" = "A"`, "", `line 1: expected ';'`},
+		{"unclosed", "\"a\" = \"A;\n", "", "line 2: unclosed string"},
+		{"comment", `/* a`, "", "line 1: unclosed comment"},
+		{"format", `"a" = "%x";`, "", "line 1: format %x is not supported"},
+		{"no format", "", "<plist><dict>\n<key>a</key><dict></dict></dict></plist>", "line 2: a: no NSStringLocalizedFormatKey string"},
+		{"no other", "", `<plist><dict><key>a</key><dict>
+<key>NSStringLocalizedFormatKey</key><string>%#@n@</string>
+<key>n</key><dict><key>NSStringFormatSpecTypeKey</key><string>NSStringPluralRuleType</string><key>one</key><string>one</string></dict>
+</dict></dict></plist>`, "line 1: a: variable n has no other rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAppleDictionary([]byte(tt.strings), []byte(tt.stringsdict))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewAppleMessageProvider(t *testing.T) {
+	p, err := NewAppleMessageProvider(fstest.MapFS{
+		"en.lproj/Localizable.strings":     {Data: []byte(testAppleStrings)},
+		"en.lproj/Localizable.stringsdict": {Data: []byte(testAppleStringsdict)},
+		"pt-BR.lproj/Localizable.strings":  {Data: []byte(`"title" = "Loja";`)},
+		"Base.lproj/Localizable.strings":   {Data: []byte(`invalid`)},
+		"en.lproj/InfoPlist.strings":       {Data: []byte(`invalid`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.MustParse("pt-BR")}, p.Languages())
+	assert.Equal(t, "Page title", p.Description(language.English, "title"))
+
+	file, line, ok := p.Source(language.English, "hello")
+	assert.True(t, ok)
+	assert.Equal(t, "en.lproj/Localizable.strings", file)
+	assert.Equal(t, 4, line)
+
+	file, line, ok = p.Source(language.English, "cart.items")
+	assert.True(t, ok)
+	assert.Equal(t, "en.lproj/Localizable.stringsdict", file)
+	assert.Equal(t, 5, line)
+
+	_, err = NewAppleMessageProvider(fstest.MapFS{"de.lproj/Localizable.strings": {Data: []byte("\n\"a\" = ")}})
+	require.ErrorContains(t, err, "de.lproj/Localizable.strings:2: ")
+}
+
+func TestWriteAppleStrings(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+# Page title
+title: "Shop"
+hello: "Hello, \"{name}\"! 50% {discount, number, integer}"
+cart:
+  items: "{count, plural, =0 {No items} one {# item} other {# items}} in {shop}"
+select: "{g, select, other {x}}"
+`)},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.EqualError(t, WriteAppleStrings(&buf, p, language.English), "select: {g, select} is not supported")
+
+	buf.Reset()
+	require.NoError(t, WriteAppleStrings(&buf, p, language.English, MobileSkipUnsupported()))
+	assert.Equal(t, `"hello" = "Hello, \"%1$@\"! 50%% %2$d";
+
+/* Page title */
+"title" = "Shop";
+
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteAppleStringsdict(&buf, p, language.English))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>cart.items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%1$#@count@ in %2$@</string>
+		<key>count</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>zero</key>
+			<string>No items</string>
+			<key>one</key>
+			<string>%1$d item</string>
+			<key>other</key>
+			<string>%1$d items</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`, buf.String())
+
+	stringsdict := buf.Bytes()
+	buf = bytes.Buffer{}
+	require.NoError(t, WriteAppleStrings(&buf, p, language.English, MobileSkipUnsupported()))
+
+	d, err := NewAppleDictionary(buf.Bytes(), stringsdict, MobileArgsFrom(p, language.English))
+	require.NoError(t, err)
+	for id, want := range map[string]string{
+		"hello":      "Hello, \"{name}\"! 50% {discount, number, integer}",
+		"cart.items": "{count, plural, =0 {No items} one {# item} other {# items}} in {shop}",
+	} {
+		msg, err := d.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, msg, id)
+	}
+}
+
+func TestWriteAppleStrings_Decimal(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{"messages.en.yaml": {Data: []byte(`
+rate: "Rate {rate, number} of {n, number, integer}"
+`)}})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAppleStrings(&buf, p, language.English))
+	assert.Equal(t, "\"rate\" = \"Rate %1$@ of %2$d\";\n\n", buf.String(), "{rate, number} is NSNumber")
+
+	apple, err := NewAppleMessageProvider(fstest.MapFS{"en.lproj/Localizable.strings": {Data: buf.Bytes()}}, MobileArgsFrom(p, language.English))
+	require.NoError(t, err)
+
+	msg, err := apple.Get(language.English, "rate")
+	require.NoError(t, err)
+	assert.Equal(t, "Rate {rate, number} of {n, number, integer}", msg)
+
+	b, err := NewBundle(WithProvider(apple))
+	require.NoError(t, err)
+	assert.Equal(t, "Rate 1.5 of 2", b.Translator("en").Trans("rate", Arg("rate", 1.5), Arg("n", 2)))
+}
@@ -0,0 +1,393 @@
+package mf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/message"
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// ArbMessageProvider loads messages from Flutter ARB files, like app_en.arb or app_pt_BR.arb.
+// Language is taken from @@locale of the file, or from its name.
+type ArbMessageProvider struct {
+	dictionaries[*ArbDictionary]
+}
+
+// arbLangSuffix matches language at the end of ARB file name, like _en, _pt_BR or _zh_Hant_TW.
+var arbLangSuffix = regexp.MustCompile(`_([a-z]{2,3}(?:_[A-Z][a-z]{3})?(?:_(?:[A-Z]{2}|[0-9]{3}))?)$`)
+
+func NewArbMessageProvider(dir fs.FS) (*ArbMessageProvider, error) {
+	provider := ArbMessageProvider{
+		dictionaries: map[language.Tag]*ArbDictionary{},
+	}
+
+	err := fs.WalkDir(dir, ".", func(p string, f fs.DirEntry, err error) error {
+		if err != nil || f.IsDir() || path.Ext(f.Name()) != ".arb" {
+			return err
+		}
+
+		return provider.loadMessages(dir, p)
+	})
+
+	return &provider, err
+}
+
+func (p *ArbMessageProvider) loadMessages(rd fs.FS, file string) error {
+	data, err := readFile(rd, file)
+	if err != nil {
+		return err
+	}
+
+	d, err := NewArbDictionary(data)
+	if err != nil {
+		return fileError(file, err)
+	}
+
+	lang := d.Locale
+	if m := arbLangSuffix.FindStringSubmatch(strings.TrimSuffix(path.Base(file), ".arb")); m != nil {
+		tag, err := language.Parse(m[1])
+		if err != nil {
+			return errors.Wrapf(err, "unable to parse language of %s", file)
+		}
+
+		if lang != language.Und && lang != tag {
+			return fmt.Errorf("unable to load %s: @@locale %s of the file does not match %s", file, lang, tag)
+		}
+
+		lang = tag
+	}
+
+	if lang == language.Und {
+		return fmt.Errorf("no lang in file %s", file)
+	}
+
+	if err := p.checkLang(file, lang); err != nil {
+		return err
+	}
+
+	d.file = file
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Description returns description of @key metadata.
+func (p *ArbMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
+}
+
+// Placeholders returns placeholders of @key metadata, nil if message has none declared.
+func (p *ArbMessageProvider) Placeholders(lang language.Tag, id string) []Placeholder {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return nil
+	}
+
+	return d.Placeholders(id)
+}
+
+// ArbDictionary is a dictionary of ARB file, a flat JSON object with messages
+// and their @key metadata:
+//
+//	{
+//	  "@@locale": "en",
+//	  "cartItems": "{count, plural, one {# item} other {# items}}",
+//	  "@cartItems": {
+//	    "description": "Items in the cart",
+//	    "placeholders": {"count": {"type": "int"}}
+//	  }
+//	}
+//
+// Other global @@ attributes are skipped.
+type ArbDictionary struct {
+	flatDictionary
+	// Locale is @@locale of the file, or language.Und.
+	Locale       language.Tag
+	descriptions map[string]string
+	placeholders map[string][]Placeholder
+}
+
+func NewArbDictionary(data []byte) (*ArbDictionary, error) {
+	d := &ArbDictionary{
+		flatDictionary: newFlatDictionary(),
+		descriptions:   make(map[string]string),
+		placeholders:   make(map[string][]Placeholder),
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return d, nil
+	}
+
+	r := &jsonReader{data: data, dec: json.NewDecoder(bytes.NewReader(data)), line: 1}
+	r.dec.UseNumber()
+
+	t, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+
+	if t != json.Delim('{') {
+		return nil, errors.New("arb root must be an object")
+	}
+
+	root, err := r.object()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.Errorf("line %d: unexpected data after the root object", r.lineAt(r.dec.InputOffset()))
+	}
+
+	// metadata is read after all messages, it could precede its message
+	var metadata []jsonEntry
+	for _, e := range root {
+		switch {
+		case e.Key == "@@locale":
+			locale, _ := e.Value.(string)
+			if d.Locale, err = language.Parse(locale); err != nil {
+				return nil, &lineError{Line: e.Line, Msg: fmt.Sprintf("invalid @@locale %q", locale)}
+			}
+		case strings.HasPrefix(e.Key, "@@"):
+		case strings.HasPrefix(e.Key, "@"):
+			metadata = append(metadata, e)
+		default:
+			msg, ok := e.Value.(string)
+			if !ok {
+				return nil, &lineError{Line: e.Line, Msg: fmt.Sprintf("message %q must be a string", e.Key)}
+			}
+
+			if first, ok := d.lines[e.Key]; ok {
+				return nil, &lineError{Line: e.Line, Msg: fmt.Sprintf("duplicate message %q, first defined on line %d", e.Key, first)}
+			}
+
+			d.flatMap[e.Key] = msg
+			d.lines[e.Key] = e.Line
+		}
+	}
+
+	for _, e := range metadata {
+		id := e.Key[1:]
+		if _, ok := d.flatMap[id]; !ok {
+			return nil, &lineError{Line: e.Line, Msg: fmt.Sprintf("metadata of unknown message %q", id)}
+		}
+
+		if err := d.loadMetadata(id, e); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// loadMetadata reads description and placeholders of @key.
+func (d *ArbDictionary) loadMetadata(id string, e jsonEntry) error {
+	entries, ok := e.Value.([]jsonEntry)
+	if !ok {
+		return &lineError{Line: e.Line, Msg: fmt.Sprintf("metadata %q must be an object", e.Key)}
+	}
+
+	for _, m := range entries {
+		switch m.Key {
+		case "description":
+			if desc, _ := m.Value.(string); desc != "" {
+				d.descriptions[id] = desc
+			}
+		case "placeholders":
+			entries, ok := m.Value.([]jsonEntry)
+			if !ok {
+				return &lineError{Line: m.Line, Msg: fmt.Sprintf("placeholders of %q must be an object", id)}
+			}
+
+			// declared placeholders are never nil, even if there are none
+			placeholders := make([]Placeholder, 0, len(entries))
+			for _, pe := range entries {
+				attrs, _ := pe.Value.([]jsonEntry)
+				placeholders = append(placeholders, arbPlaceholder(pe.Key, attrs))
+			}
+
+			d.placeholders[id] = placeholders
+		}
+	}
+
+	return nil
+}
+
+func arbPlaceholder(name string, attrs []jsonEntry) Placeholder {
+	p := Placeholder{Name: name}
+	for _, a := range attrs {
+		v, _ := a.Value.(string)
+		switch a.Key {
+		case "type":
+			p.Type = v
+		case "format":
+			p.Format = v
+		case "example":
+			p.Example = v
+		case "description":
+			p.Description = v
+		}
+	}
+
+	return p
+}
+
+// Description returns description of @key metadata.
+func (d *ArbDictionary) Description(id string) string {
+	return d.descriptions[id]
+}
+
+// Placeholders returns placeholders of @key metadata, nil if message has none declared.
+func (d *ArbDictionary) Placeholders(id string) []Placeholder {
+	return d.placeholders[id]
+}
+
+// WriteArb writes messages of the provider for the language as ARB file,
+// e.g. to export yaml catalogs to Flutter. Descriptions and placeholders become
+// @key metadata. Placeholders are taken from PlaceholderProvider, or from arguments
+// of messages: numbers are num, dates are DateTime and others are String.
+// Ids are written as is, Flutter requires them to be Dart identifiers.
+func WriteArb(w io.Writer, p ListableProvider, lang language.Tag) error {
+	descriptions, _ := p.(DescriptionProvider)
+	declared, _ := p.(PlaceholderProvider)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "{\n  \"@@locale\": %s", arbString(lang.String()))
+	for id := range p.IDs(lang) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		if messageSyntax(p, lang, id) != SyntaxICU {
+			return errors.Errorf("%s: MessageFormat 2 messages are not supported by ARB", id)
+		}
+
+		fmt.Fprintf(bw, ",\n  %s: %s", arbString(id), arbString(msg))
+
+		var desc string
+		if descriptions != nil {
+			desc = descriptions.Description(lang, id)
+		}
+
+		var placeholders []Placeholder
+		if declared != nil {
+			placeholders = declared.Placeholders(lang, id)
+		}
+
+		if placeholders == nil {
+			if placeholders, err = messagePlaceholders(msg, lang); err != nil {
+				return errors.Wrapf(err, "unable to build %s", id)
+			}
+		}
+
+		if desc == "" && len(placeholders) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(bw, ",\n  %s: {", arbString("@"+id))
+		sep := "\n"
+		if desc != "" {
+			fmt.Fprintf(bw, "%s    \"description\": %s", sep, arbString(desc))
+			sep = ",\n"
+		}
+
+		if len(placeholders) > 0 {
+			fmt.Fprintf(bw, "%s    \"placeholders\": {", sep)
+			for i, ph := range placeholders {
+				if i > 0 {
+					bw.WriteString(",")
+				}
+
+				fmt.Fprintf(bw, "\n      %s: {", arbString(ph.Name))
+				attrs := []string{}
+				for _, a := range [][2]string{{"type", ph.Type}, {"format", ph.Format}, {"example", ph.Example}, {"description", ph.Description}} {
+					if a[1] != "" {
+						attrs = append(attrs, fmt.Sprintf("%q: %s", a[0], arbString(a[1])))
+					}
+				}
+
+				bw.WriteString(strings.Join(attrs, ", ") + "}")
+			}
+
+			bw.WriteString("\n    }")
+		}
+
+		bw.WriteString("\n  }")
+	}
+
+	bw.WriteString("\n}\n")
+
+	return bw.Flush()
+}
+
+// messagePlaceholders returns arguments of the message as placeholders.
+func messagePlaceholders(msg string, lang language.Tag) ([]Placeholder, error) {
+	m, err := parse.Parse(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	eval, err := message.Build(*m, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	var placeholders []Placeholder
+	index := map[string]int{}
+	for _, arg := range message.Args(eval) {
+		typ := "String"
+		switch arg.Kind {
+		case message.ArgNumber:
+			typ = "num"
+		case message.ArgTime:
+			typ = "DateTime"
+		}
+
+		i, ok := index[arg.Name]
+		if !ok {
+			index[arg.Name] = len(placeholders)
+			placeholders = append(placeholders, Placeholder{Name: arg.Name, Type: typ})
+
+			continue
+		}
+
+		// plain {arg} is String, other uses are more specific
+		if arg.Kind != message.ArgString && arg.Kind != message.ArgSelectKey {
+			placeholders[i].Type = typ
+		}
+	}
+
+	return placeholders, nil
+}
+
+func arbString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+var (
+	_ ListableProvider    = (*ArbMessageProvider)(nil)
+	_ SourceProvider      = (*ArbMessageProvider)(nil)
+	_ DescriptionProvider = (*ArbMessageProvider)(nil)
+	_ PlaceholderProvider = (*ArbMessageProvider)(nil)
+)
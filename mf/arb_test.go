@@ -0,0 +1,187 @@
+package mf
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+const testArb = `{
+  "@@locale": "en",
+  "@@last_modified": "2024-01-01",
+  "@cartItems": {
+    "description": "Items in the cart",
+    "placeholders": {
+      "count": {"type": "int", "format": "compact", "example": 5}
+    }
+  },
+  "cartItems": "{count, plural, =0{No items} one{# item} other{# items}}",
+  "hello": "Hello, {name}!",
+  "@hello": {"placeholders": {}},
+  "title": "Shop",
+  "@title": {"description": "Page title"}
+}
+`
+
+func TestNewArbDictionary(t *testing.T) {
+	d, err := NewArbDictionary([]byte(testArb))
+	require.NoError(t, err)
+
+	assert.Equal(t, language.English, d.Locale)
+	assert.Equal(t, []string{"cartItems", "hello", "title"}, slices.Collect(d.IDs()))
+
+	msg, err := d.Get("cartItems")
+	require.NoError(t, err)
+	assert.Equal(t, "{count, plural, =0{No items} one{# item} other{# items}}", msg)
+	assert.Equal(t, 10, d.Line("cartItems"))
+
+	assert.Equal(t, "Items in the cart", d.Description("cartItems"))
+	assert.Equal(t, "Page title", d.Description("title"))
+	assert.Equal(t, []Placeholder{{Name: "count", Type: "int", Format: "compact", Example: "5"}}, d.Placeholders("cartItems"))
+	assert.Equal(t, []Placeholder{}, d.Placeholders("hello"), "declared without placeholders")
+	assert.Nil(t, d.Placeholders("title"))
+}
+
+func TestNewArbDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"root", `[]`, "arb root must be an object"},
+		{"locale", `{"@@locale": "???"}`, `line 1: invalid @@locale "???"`},
+		{"message", "{\n\"a\": {}}", `line 2: message "a" must be a string`},
+		{"duplicate", "{\"a\": \"A\",\n\"a\": \"B\"}", `line 2: duplicate message "a", first defined on line 1`},
+		{"unknown", "{\"a\": \"A\",\n\"@b\": {}}", `line 2: metadata of unknown message "b"`},
+		{"metadata", `{"a": "A", "@a": "A"}`, `line 1: metadata "@a" must be an object`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewArbDictionary([]byte(tt.in))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewArbMessageProvider(t *testing.T) {
+	p, err := NewArbMessageProvider(fstest.MapFS{
+		"l10n/app_en.arb":       {Data: []byte(testArb)},
+		"l10n/my_app_pt_BR.arb": {Data: []byte(`{"title": "Loja"}`)},
+		"l10n/intl_sr_Latn.arb": {Data: []byte(`{"title": "Prodavnica"}`)},
+		"l10n/german.arb":       {Data: []byte(`{"@@locale": "de", "title": "Laden"}`)},
+		"l10n/app_en.json":      {Data: []byte(`invalid`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.German, language.English, language.MustParse("pt-BR"), language.MustParse("sr-Latn")}, p.Languages())
+	assert.Equal(t, "Page title", p.Description(language.English, "title"))
+	assert.Equal(t, "count", p.Placeholders(language.English, "cartItems")[0].Name)
+
+	file, line, ok := p.Source(language.English, "hello")
+	assert.True(t, ok)
+	assert.Equal(t, "l10n/app_en.arb", file)
+	assert.Equal(t, 11, line)
+
+	_, err = NewArbMessageProvider(fstest.MapFS{"app_de.arb": {Data: []byte(testArb)}})
+	require.EqualError(t, err, "unable to load app_de.arb: @@locale en of the file does not match de")
+
+	_, err = NewArbMessageProvider(fstest.MapFS{"app.arb": {Data: []byte(`{}`)}})
+	require.EqualError(t, err, "no lang in file app.arb")
+
+	_, err = NewArbMessageProvider(fstest.MapFS{"app_de.arb": {Data: []byte("{\n\"a\": 1,\n\"@a\": []}")}})
+	require.EqualError(t, err, `app_de.arb:3: metadata "@a" must be an object`)
+
+	b, err := NewBundle(WithArbProvider(fstest.MapFS{"app_en.arb": {Data: []byte(testArb)}}))
+	require.NoError(t, err)
+	assert.Equal(t, "No items", b.Translator("en").Trans("cartItems", Arg("count", 0)))
+}
+
+func TestCheck_Placeholders(t *testing.T) {
+	b, err := NewBundle(WithArbProvider(fstest.MapFS{
+		"app_en.arb": {Data: []byte(`{
+  "ok": "{count, plural, one {# item} other {# items}} of {name}",
+  "@ok": {"placeholders": {"count": {"type": "int"}, "name": {}}},
+  "typed": "{when, date, short}",
+  "@typed": {"placeholders": {"when": {"type": "String"}}},
+  "unused": "Hello!",
+  "@unused": {"placeholders": {"name": {"type": "String"}}},
+  "undeclared": "Hello, {name}!",
+  "@undeclared": {"placeholders": {}}
+}`)},
+	}))
+	require.NoError(t, err)
+
+	issues, err := Check(b, language.English)
+	require.NoError(t, err)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+
+	assert.Equal(t, []string{
+		"app_en.arb:4: en typed: argument {when} is used as time, placeholder type is String",
+		"app_en.arb:8: en undeclared: argument {name} is not declared in placeholders",
+		"app_en.arb:6: en unused: placeholder {name} is not used",
+	}, got)
+}
+
+func TestWriteArb(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+cart:
+  # Items in the cart
+  items: "{count, plural, one {# item} other {# items}} in <{shop}>"
+hello: "Hello, {name}! {name, select, other {x}}"
+due: "{when, date, short}"
+title: Shop
+`)},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteArb(&buf, p, language.English))
+	assert.Equal(t, `{
+  "@@locale": "en",
+  "cart.items": "{count, plural, one {# item} other {# items}} in <{shop}>",
+  "@cart.items": {
+    "description": "Items in the cart",
+    "placeholders": {
+      "count": {"type": "num"},
+      "shop": {"type": "String"}
+    }
+  },
+  "due": "{when, date, short}",
+  "@due": {
+    "placeholders": {
+      "when": {"type": "DateTime"}
+    }
+  },
+  "hello": "Hello, {name}! {name, select, other {x}}",
+  "@hello": {
+    "placeholders": {
+      "name": {"type": "String"}
+    }
+  },
+  "title": "Shop"
+}
+`, buf.String())
+
+	arb, err := NewArbMessageProvider(fstest.MapFS{"app_en.arb": {Data: buf.Bytes()}})
+	require.NoError(t, err)
+	assert.Equal(t, "Items in the cart", arb.Description(language.English, "cart.items"))
+
+	var again bytes.Buffer
+	require.NoError(t, WriteArb(&again, arb, language.English))
+	assert.Equal(t, buf.String(), again.String(), "placeholders are kept")
+
+	mf2, err := NewYamlMessageProvider(fstest.MapFS{"messages.en.yaml": {Data: []byte(`a: !mf2 "{$x}"`)}})
+	require.NoError(t, err)
+	require.EqualError(t, WriteArb(&buf, mf2, language.English), "a: MessageFormat 2 messages are not supported by ARB")
+}
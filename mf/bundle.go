@@ -2,22 +2,51 @@ package mf
 
 import (
 	"io/fs"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
 
+	"github.com/fullpipe/icu-mf/message"
 	"github.com/pkg/errors"
 	"golang.org/x/text/language"
 )
 
 type Bundle interface {
+	// Translator returns translator for BCP 47 language,
+	// invalid language is replaced with the default one.
 	Translator(lang string) Translator
+	// TranslatorFor returns translator for the language. Translators are cached
+	// for languages bundle knows: languages of provider, languages with fallbacks
+	// or used as fallbacks, and the default one. Others are created on every call.
+	TranslatorFor(tag language.Tag) Translator
+	// TranslatorForAcceptLanguage returns translator for the most preferred language
+	// from Accept-Language header that bundle has messages for. The language is
+	// replaced with the bundle language it matches, unless bundle knows it,
+	// see TranslatorFor.
+	TranslatorForAcceptLanguage(header string) Translator
+	// FallbackChain returns languages to look for a message in, starting with tag itself.
+	FallbackChain(tag language.Tag) []language.Tag
+	// Languages returns languages with messages, if provider is a ListableProvider.
+	Languages() []language.Tag
+	// IDs returns ids of messages for the language, if provider is a ListableProvider.
+	IDs(lang language.Tag) iter.Seq[string]
 }
 
 type bundle struct {
-	fallbacks   map[language.Tag]language.Tag
+	fallbacks   map[language.Tag][]language.Tag
 	translators map[language.Tag]Translator
 	provider    MessageProvider
+	mu          sync.RWMutex
 
 	defaultLang         language.Tag
 	defaultErrorHandler ErrorHandler
+	buildOptions        []message.BuildOption
+	strictValidation    bool
+
+	// languages provider has, if it is able to list them
+	languages []language.Tag
+	matcher   language.Matcher
 }
 
 type ErrorHandler func(err error, id string, ctx map[string]any)
@@ -26,7 +55,7 @@ type BundleOption func(b *bundle) error
 
 func NewBundle(options ...BundleOption) (Bundle, error) {
 	bundle := &bundle{
-		fallbacks:   make(map[language.Tag]language.Tag),
+		fallbacks:   make(map[language.Tag][]language.Tag),
 		translators: make(map[language.Tag]Translator),
 		defaultLang: language.Und,
 		defaultErrorHandler: func(_ error, _ string, _ map[string]any) {
@@ -44,11 +73,22 @@ func NewBundle(options ...BundleOption) (Bundle, error) {
 		return nil, errors.New("you must add a message provider with WithFSProvider or WithProvider")
 	}
 
+	if lister, ok := bundle.provider.(ListableProvider); ok {
+		bundle.languages = lister.Languages()
+		bundle.matcher = language.NewMatcher(bundle.languages)
+	}
+
 	// Check for cyclic fallbacks
 	if err := checkCyclicFallbacks(bundle.fallbacks); err != nil {
 		return nil, err
 	}
 
+	if bundle.strictValidation {
+		if err := bundle.validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return bundle, nil
 }
 
@@ -58,34 +98,155 @@ func (b *bundle) Translator(lang string) Translator {
 		tag = b.defaultLang
 	}
 
-	if tr, ok := b.translators[tag]; ok {
+	return b.TranslatorFor(tag)
+}
+
+func (b *bundle) TranslatorForAcceptLanguage(header string) Translator {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return b.TranslatorFor(b.defaultLang)
+	}
+
+	if b.matcher == nil {
+		return b.TranslatorFor(tags[0])
+	}
+
+	// headers are under client control, so translators are cached for matched languages only
+	for _, tag := range tags {
+		if _, idx, confidence := b.matcher.Match(tag); confidence != language.No {
+			if !b.known(tag) {
+				tag = b.languages[idx]
+			}
+
+			return b.TranslatorFor(tag)
+		}
+	}
+
+	return b.TranslatorFor(b.defaultLang)
+}
+
+func (b *bundle) TranslatorFor(tag language.Tag) Translator {
+	if !b.known(tag) {
+		return b.getTranslator(tag)
+	}
+
+	b.mu.RLock()
+	tr, ok := b.translators[tag]
+	b.mu.RUnlock()
+
+	if ok {
 		return tr
 	}
 
-	tr := b.getTranslator(tag)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tr = b.getTranslator(tag)
 	b.translators[tag] = tr
 
 	return tr
 }
 
-func (b *bundle) getTranslator(tag language.Tag) Translator {
-	if tr, ok := b.translators[tag]; ok {
-		return tr
+// known reports if translator for the language is cached,
+// so the cache does not grow with arbitrary languages of requests.
+func (b *bundle) known(tag language.Tag) bool {
+	if tag == b.defaultLang || slices.Contains(b.languages, tag) {
+		return true
 	}
 
-	var fallback Translator
-	if fallbackTag, hasFallback := b.fallbacks[tag]; hasFallback {
-		fallback = b.getTranslator(fallbackTag)
-	} else if tag != b.defaultLang {
-		fallback = b.getTranslator(b.defaultLang)
+	if _, ok := b.fallbacks[tag]; ok {
+		return true
 	}
 
-	return &translator{
-		provider:     b.provider,
-		fallback:     fallback,
-		errorHandler: b.defaultErrorHandler,
-		lang:         tag,
+	for _, to := range b.fallbacks {
+		if slices.Contains(to, tag) {
+			return true
+		}
 	}
+
+	return false
+}
+
+func (b *bundle) Languages() []language.Tag {
+	return slices.Clone(b.languages)
+}
+
+func (b *bundle) IDs(lang language.Tag) iter.Seq[string] {
+	lister, ok := b.provider.(ListableProvider)
+	if !ok {
+		return func(func(string) bool) {}
+	}
+
+	return lister.IDs(lang)
+}
+
+func (b *bundle) FallbackChain(tag language.Tag) []language.Tag {
+	return b.fallbackChain(tag)
+}
+
+func (b *bundle) getTranslator(tag language.Tag) *translator {
+	chain := b.fallbackChain(tag)
+
+	var tr *translator
+	for i := len(chain) - 1; i >= 0; i-- {
+		tr = &translator{
+			provider:     b.provider,
+			fallback:     tr,
+			errorHandler: b.defaultErrorHandler,
+			lang:         chain[i],
+			buildOptions: b.buildOptions,
+		}
+	}
+
+	return tr
+}
+
+// fallbackChain returns languages to look for a message in, starting with tag itself.
+// Every language falls back to its explicit fallbacks in order, or to its CLDR parent,
+// like es-MX -> es-419 -> es. Languages without parent are matched against
+// provider languages, and the default language goes last.
+func (b *bundle) fallbackChain(tag language.Tag) []language.Tag {
+	var chain []language.Tag
+	seen := map[language.Tag]bool{}
+
+	var add func(t language.Tag)
+	add = func(t language.Tag) {
+		if seen[t] {
+			return
+		}
+
+		seen[t] = true
+		chain = append(chain, t)
+
+		if next, ok := b.fallbacks[t]; ok {
+			for _, n := range next {
+				add(n)
+			}
+		} else if parent := t.Parent(); parent != language.Und {
+			add(parent)
+		} else if match, ok := b.match(t); ok {
+			add(match)
+		}
+	}
+
+	add(tag)
+	add(b.defaultLang)
+
+	return chain
+}
+
+// match finds the closest language that provider has.
+func (b *bundle) match(tag language.Tag) (language.Tag, bool) {
+	if b.matcher == nil || tag == language.Und {
+		return language.Und, false
+	}
+
+	_, idx, confidence := b.matcher.Match(tag)
+	if confidence < language.High {
+		return language.Und, false
+	}
+
+	return b.languages[idx], true
 }
 
 func WithDefaultLangFallback(l language.Tag) BundleOption {
@@ -104,6 +265,56 @@ func WithYamlProvider(dir fs.FS) BundleOption {
 	}
 }
 
+// WithJsonProvider loads messages from messages.<lang>.json files, see JsonDictionary.
+func WithJsonProvider(dir fs.FS) BundleOption {
+	return func(b *bundle) error {
+		provider, err := NewJsonMessageProvider(dir)
+		b.provider = provider
+
+		return err
+	}
+}
+
+// WithTomlProvider loads messages from messages.<lang>.toml files, see TomlDictionary.
+func WithTomlProvider(dir fs.FS) BundleOption {
+	return func(b *bundle) error {
+		provider, err := NewTomlMessageProvider(dir)
+		b.provider = provider
+
+		return err
+	}
+}
+
+// WithPoProvider loads messages from messages.<lang>.po or .mo files, see PoDictionary.
+func WithPoProvider(dir fs.FS, opts ...PoOption) BundleOption {
+	return func(b *bundle) error {
+		provider, err := NewPoMessageProvider(dir, opts...)
+		b.provider = provider
+
+		return err
+	}
+}
+
+// WithXliffProvider loads translations from messages.<lang>.xlf or .xliff files, see XliffDictionary.
+func WithXliffProvider(dir fs.FS) BundleOption {
+	return func(b *bundle) error {
+		provider, err := NewXliffMessageProvider(dir)
+		b.provider = provider
+
+		return err
+	}
+}
+
+// WithArbProvider loads messages from Flutter ARB files, like app_en.arb, see ArbDictionary.
+func WithArbProvider(dir fs.FS) BundleOption {
+	return func(b *bundle) error {
+		provider, err := NewArbMessageProvider(dir)
+		b.provider = provider
+
+		return err
+	}
+}
+
 func WithProvider(provider MessageProvider) BundleOption {
 	return func(b *bundle) error {
 		b.provider = provider
@@ -112,7 +323,11 @@ func WithProvider(provider MessageProvider) BundleOption {
 	}
 }
 
-func WithLangFallback(from language.Tag, to language.Tag) BundleOption {
+// WithLangFallback sets languages to look for a message in, in order,
+// when there is no message for the from language.
+//
+//	mf.WithLangFallback(language.Ukrainian, language.Russian, language.English)
+func WithLangFallback(from language.Tag, to ...language.Tag) BundleOption {
 	return func(b *bundle) error {
 		b.fallbacks[from] = to
 
@@ -128,29 +343,49 @@ func WithErrorHandler(handler ErrorHandler) BundleOption {
 	}
 }
 
+// WithStrictSelect reports missing select arguments, and arguments of unsupported
+// types, to the error handler instead of silently using the 'other' case.
+func WithStrictSelect() BundleOption {
+	return func(b *bundle) error {
+		b.buildOptions = append(b.buildOptions, message.WithStrictSelect())
+		return nil
+	}
+}
+
 // checkCyclicFallbacks checks for cyclic fallbacks to prevent infinite loops
-func checkCyclicFallbacks(fallbacks map[language.Tag]language.Tag) error {
-	visited := make(map[language.Tag]bool)
+func checkCyclicFallbacks(fallbacks map[language.Tag][]language.Tag) error {
+	tags := make([]language.Tag, 0, len(fallbacks))
 	for tag := range fallbacks {
-		if hasCycle(tag, fallbacks, visited) {
-			return errors.New("cyclic fallback detected")
+		tags = append(tags, tag)
+	}
+
+	// sort for the same error on every run
+	slices.SortFunc(tags, func(a, b language.Tag) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, tag := range tags {
+		if path := findCycle(tag, fallbacks, nil); path != nil {
+			return &FallbackCycleError{Path: path}
 		}
 	}
+
 	return nil
 }
 
-func hasCycle(tag language.Tag, fallbacks map[language.Tag]language.Tag, visited map[language.Tag]bool) bool {
-	if visited[tag] {
-		return true
+// findCycle returns the first found cycle, like [en es en], or nil.
+func findCycle(tag language.Tag, fallbacks map[language.Tag][]language.Tag, path []language.Tag) []language.Tag {
+	if i := slices.Index(path, tag); i >= 0 {
+		return append(slices.Clone(path[i:]), tag)
 	}
 
-	visited[tag] = true
-	defer delete(visited, tag)
+	path = append(path, tag)
 
-	next, ok := fallbacks[tag]
-	if !ok {
-		return false
+	for _, next := range fallbacks[tag] {
+		if cycle := findCycle(next, fallbacks, path); cycle != nil {
+			return cycle
+		}
 	}
 
-	return hasCycle(next, fallbacks, visited)
+	return nil
 }
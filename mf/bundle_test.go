@@ -1,8 +1,11 @@
 package mf
 
 import (
+	"fmt"
 	"reflect"
 	"runtime"
+	"slices"
+	"sync"
 	"testing"
 	"testing/fstest"
 
@@ -25,7 +28,7 @@ func TestNewBundle(t *testing.T) {
 
 func TestWithDefaultLangFallback(t *testing.T) {
 	b := &bundle{
-		fallbacks:   map[language.Tag]language.Tag{},
+		fallbacks:   map[language.Tag][]language.Tag{},
 		translators: map[language.Tag]Translator{},
 
 		defaultLang:         language.Und,
@@ -39,21 +42,23 @@ func TestWithDefaultLangFallback(t *testing.T) {
 
 func TestWithLangFallback(t *testing.T) {
 	b := &bundle{
-		fallbacks:   map[language.Tag]language.Tag{},
+		fallbacks:   map[language.Tag][]language.Tag{},
 		translators: map[language.Tag]Translator{},
 
 		defaultLang:         language.Und,
 		defaultErrorHandler: func(_ error, _ string, _ map[string]any) {},
 	}
 
-	assert.Equal(t, language.Und, b.fallbacks[language.AmericanEnglish])
+	assert.Empty(t, b.fallbacks[language.AmericanEnglish])
 	require.NoError(t, WithLangFallback(language.AmericanEnglish, language.English)(b))
-	assert.Equal(t, language.English, b.fallbacks[language.AmericanEnglish])
+	assert.Equal(t, []language.Tag{language.English}, b.fallbacks[language.AmericanEnglish])
+	require.NoError(t, WithLangFallback(language.Ukrainian, language.Russian, language.English)(b))
+	assert.Equal(t, []language.Tag{language.Russian, language.English}, b.fallbacks[language.Ukrainian])
 }
 
 func TestWithErrorHandler(t *testing.T) {
 	b := &bundle{
-		fallbacks:   map[language.Tag]language.Tag{},
+		fallbacks:   map[language.Tag][]language.Tag{},
 		translators: map[language.Tag]Translator{},
 
 		defaultLang:         language.Und,
@@ -98,52 +103,291 @@ func TestBundle_Translator(t *testing.T) {
 	assert.Equal(t, "none_id", b.Translator("en").Trans("none_id"), "dummy translator if nothing works")
 }
 
+func TestBundle_TranslatorParentLocales(t *testing.T) {
+	b, err := NewBundle(
+		WithDefaultLangFallback(language.English),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml":      {Data: []byte("foo: en")},
+			"messages.es.yaml":      {Data: []byte("foo: es\nbar: es")},
+			"messages.es-419.yaml":  {Data: []byte("foo: es-419")},
+			"messages.zh.yaml":      {Data: []byte("foo: zh")},
+			"messages.zh-Hant.yaml": {Data: []byte("foo: zh-Hant")},
+			"messages.no.yaml":      {Data: []byte("foo: no")},
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", b.Translator("en-US").Trans("foo"))
+	assert.Equal(t, "es-419", b.Translator("es-MX").Trans("foo"))
+	assert.Equal(t, "es", b.Translator("es-MX").Trans("bar"), "es-MX -> es-419 -> es")
+	assert.Equal(t, "es", b.Translator("es-ES").Trans("foo"))
+	assert.Equal(t, "zh-Hant", b.Translator("zh-Hant-HK").Trans("foo"), "not zh")
+	assert.Equal(t, "zh-Hant", b.Translator("zh-TW").Trans("foo"))
+	assert.Equal(t, "zh", b.Translator("zh-CN").Trans("foo"))
+	assert.Equal(t, "no", b.Translator("nb").Trans("foo"), "matched by language.Matcher")
+	assert.Equal(t, "en", b.Translator("de-AT").Trans("foo"))
+
+	b, err = NewBundle(WithYamlProvider(fstest.MapFS{}))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", b.Translator("de-AT").Trans("foo"), "no languages to match")
+}
+
+func TestBundle_fallbackChain(t *testing.T) {
+	b, err := NewBundle(
+		WithDefaultLangFallback(language.English),
+		WithLangFallback(language.Portuguese, language.Spanish),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml":      {Data: []byte("foo: en")},
+			"messages.es.yaml":      {Data: []byte("foo: es")},
+			"messages.sr-Latn.yaml": {Data: []byte("foo: sr-Latn")},
+		}),
+	)
+	require.NoError(t, err)
+
+	bb := b.(*bundle)
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"en", []string{"en"}},
+		{"en-GB", []string{"en-GB", "en-001", "en"}},
+		{"es-MX", []string{"es-MX", "es-419", "es", "en"}},
+		{"pt-BR", []string{"pt-BR", "pt", "es", "en"}},
+		{"sr-ME", []string{"sr-ME", "sr-Latn", "en"}},
+		{"de", []string{"de", "en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			var got []string
+			for _, tag := range bb.fallbackChain(language.MustParse(tt.tag)) {
+				got = append(got, tag.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBundle_FallbackChain(t *testing.T) {
+	b, err := NewBundle(
+		WithDefaultLangFallback(language.English),
+		WithLangFallback(language.Ukrainian, language.Russian, language.English),
+		WithLangFallback(language.Catalan, language.Spanish, language.French),
+		WithLangFallback(language.French, language.Italian),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: en")},
+			"messages.ru.yaml": {Data: []byte("foo: ru")},
+			"messages.it.yaml": {Data: []byte("foo: it\nbar: it")},
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]language.Tag{language.Ukrainian, language.Russian, language.English},
+		b.FallbackChain(language.Ukrainian),
+	)
+	assert.Equal(t,
+		[]language.Tag{language.Catalan, language.Spanish, language.French, language.Italian, language.English},
+		b.FallbackChain(language.Catalan),
+	)
+
+	assert.Equal(t, "ru", b.Translator("uk").Trans("foo"))
+	assert.Equal(t, "it", b.Translator("ca").Trans("foo"))
+	assert.Equal(t, "it", b.Translator("ca-ES").Trans("bar"))
+
+	_, err = NewBundle(
+		WithLangFallback(language.Ukrainian, language.Russian, language.English),
+		WithLangFallback(language.English, language.Ukrainian),
+		WithProvider(new(MockedProvider)),
+	)
+	require.EqualError(t, err, "cyclic fallback detected: en -> uk -> en")
+}
+
+func TestBundle_TranslatorFor(t *testing.T) {
+	b, err := NewBundle(
+		WithDefaultLangFallback(language.English),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: en\nbar: en")},
+			"messages.es.yaml": {Data: []byte("foo: es")},
+		}),
+	)
+	require.NoError(t, err)
+
+	tr := b.TranslatorFor(language.MustParse("es-MX"))
+	assert.Equal(t, language.MustParse("es-MX"), tr.Lang())
+	assert.NotSame(t, tr, b.Translator("es-MX"), "translators are cached for known languages only")
+	assert.Same(t, b.Translator("es"), b.Translator("es"), "translators are reused")
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"es-MX,es;q=0.9,en;q=0.8", "es"},
+		{"de-DE,de;q=0.9,es;q=0.8", "es"},
+		{"fr;q=0.5,es;q=0.8", "es"},
+		{"de", "en"},
+		{"", "en"},
+		{"$$$", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, language.MustParse(tt.want), b.TranslatorForAcceptLanguage(tt.header).Lang())
+		})
+	}
+}
+
+func TestBundle_TranslatorCache(t *testing.T) {
+	b, err := NewBundle(
+		WithDefaultLangFallback(language.English),
+		WithLangFallback(language.MustParse("es-MX"), language.Spanish),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: en")},
+			"messages.es.yaml": {Data: []byte("foo: es")},
+		}),
+	)
+	require.NoError(t, err)
+
+	es := b.Translator("es")
+	regions := []string{"AR", "CL", "CO", "ES", "419"}
+	for i := range 1000 {
+		header := fmt.Sprintf("es-%s-x-r%d;q=0.9,en;q=0.8", regions[i%len(regions)], i)
+		require.Same(t, es, b.TranslatorForAcceptLanguage(header), header)
+	}
+
+	assert.Same(t, b.Translator("es-MX"), b.TranslatorForAcceptLanguage("es-MX"), "language with fallbacks is kept")
+	assert.Equal(t, language.MustParse("es-MX"), b.TranslatorForAcceptLanguage("es-MX").Lang())
+	assert.Len(t, b.(*bundle).translators, 2, "es and es-MX only")
+}
+
+func TestBundle_Languages(t *testing.T) {
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: en\nbar: en")},
+			"messages.es.yaml": {Data: []byte("foo: es")},
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.Spanish}, b.Languages())
+	assert.Equal(t, []string{"bar", "foo"}, slices.Collect(b.IDs(language.English)))
+	assert.Equal(t, []string{"foo"}, slices.Collect(b.IDs(language.Spanish)))
+
+	b, err = NewBundle(WithProvider(new(MockedProvider)))
+	require.NoError(t, err)
+
+	assert.Empty(t, b.Languages(), "provider is not listable")
+	assert.Empty(t, slices.Collect(b.IDs(language.English)))
+}
+
+func TestBundle_TranslatorConcurrent(t *testing.T) {
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: en")},
+		}),
+	)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, lang := range []string{"en", "es", "ru", "en", "es", "ru"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Translator(lang).Trans("foo")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithStrictSelect(t *testing.T) {
+	var handledErr error
+	b, err := NewBundle(
+		WithStrictSelect(),
+		WithErrorHandler(func(err error, _ string, _ map[string]any) {
+			handledErr = err
+		}),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("foo: '{lang, select, en {en} other {other}}'")},
+		}),
+	)
+	require.NoError(t, err)
+
+	tr := b.Translator("en")
+	assert.Equal(t, "en", tr.Trans("foo", Arg("lang", "en")))
+	require.NoError(t, handledErr)
+
+	assert.Equal(t, "foo", tr.Trans("foo"), "missing select argument is an error")
+	require.Error(t, handledErr)
+}
+
 func TestCheckCyclicFallbacks(t *testing.T) {
 	tests := []struct {
 		name      string
-		fallbacks map[language.Tag]language.Tag
-		wantErr   bool
+		fallbacks map[language.Tag][]language.Tag
+		wantPath  string
 	}{
 		{
 			name: "no cycle",
-			fallbacks: map[language.Tag]language.Tag{
-				language.English: language.Spanish,
-				language.Spanish: language.Portuguese,
+			fallbacks: map[language.Tag][]language.Tag{
+				language.English: {language.Spanish},
+				language.Spanish: {language.Portuguese},
+			},
+		},
+		{
+			name: "no cycle with lists",
+			fallbacks: map[language.Tag][]language.Tag{
+				language.Ukrainian: {language.Russian, language.English},
+				language.Catalan:   {language.Spanish, language.French, language.English},
+				language.Russian:   {language.English},
 			},
-			wantErr: false,
 		},
 		{
 			name: "direct cycle",
-			fallbacks: map[language.Tag]language.Tag{
-				language.English: language.Spanish,
-				language.Spanish: language.English,
+			fallbacks: map[language.Tag][]language.Tag{
+				language.English: {language.Spanish},
+				language.Spanish: {language.English},
 			},
-			wantErr: true,
+			wantPath: "en -> es -> en",
 		},
 		{
 			name: "indirect cycle",
-			fallbacks: map[language.Tag]language.Tag{
-				language.English:    language.Spanish,
-				language.Spanish:    language.Portuguese,
-				language.Portuguese: language.English,
+			fallbacks: map[language.Tag][]language.Tag{
+				language.English:    {language.Spanish},
+				language.Spanish:    {language.Portuguese},
+				language.Portuguese: {language.English},
+			},
+			wantPath: "en -> es -> pt -> en",
+		},
+		{
+			name: "cycle in the middle of the list",
+			fallbacks: map[language.Tag][]language.Tag{
+				language.Catalan: {language.Spanish, language.French, language.English},
+				language.French:  {language.Italian, language.Catalan},
+			},
+			wantPath: "ca -> fr -> ca",
+		},
+		{
+			name: "self cycle",
+			fallbacks: map[language.Tag][]language.Tag{
+				language.Catalan: {language.Catalan},
 			},
-			wantErr: true,
+			wantPath: "ca -> ca",
 		},
 		{
 			name:      "no fallbacks",
-			fallbacks: map[language.Tag]language.Tag{},
-			wantErr:   false,
+			fallbacks: map[language.Tag][]language.Tag{},
 		},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := checkCyclicFallbacks(tt.fallbacks)
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
+			if tt.wantPath == "" {
 				assert.NoError(t, err)
+
+				return
 			}
+
+			var cycleErr *FallbackCycleError
+			require.ErrorAs(t, err, &cycleErr)
+			assert.Equal(t, "cyclic fallback detected: "+tt.wantPath, err.Error())
 		})
 	}
 }
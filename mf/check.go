@@ -0,0 +1,329 @@
+package mf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/message"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// IssueKind is a kind of problem found by Check.
+type IssueKind int
+
+const (
+	// IssueInvalidMessage means message could not be compiled.
+	IssueInvalidMessage IssueKind = iota
+	// IssueArgMissing means translation does not use an argument of the source message.
+	IssueArgMissing
+	// IssueArgAdded means translation uses an argument the source message does not have.
+	IssueArgAdded
+	// IssueArgType means argument is used differently, e.g. number in source and date in translation.
+	IssueArgType
+	// IssueSelectKeyMissing means translation has no select case the source message has.
+	IssueSelectKeyMissing
+	// IssuePluralCategoryMissing means plural has no case for a CLDR category of the language.
+	IssuePluralCategoryMissing
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueInvalidMessage:
+		return "invalid message"
+	case IssueArgMissing:
+		return "missing argument"
+	case IssueArgAdded:
+		return "added argument"
+	case IssueArgType:
+		return "argument type mismatch"
+	case IssueSelectKeyMissing:
+		return "missing select key"
+	case IssuePluralCategoryMissing:
+		return "missing plural category"
+	default:
+		return fmt.Sprintf("IssueKind(%d)", int(k))
+	}
+}
+
+// Issue is a problem found by Check in a single message.
+type Issue struct {
+	Kind IssueKind
+	Lang language.Tag
+	ID   string
+	// File and Line where message is defined, if provider implements SourceProvider.
+	File string
+	Line int
+	// Arg is the name of the argument, if any.
+	Arg string
+	// Key is the missing select key or plural category.
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	prefix := ""
+	if i.File != "" {
+		prefix = i.File + ": "
+		if i.Line > 0 {
+			prefix = fmt.Sprintf("%s:%d: ", i.File, i.Line)
+		}
+	}
+
+	return fmt.Sprintf("%s%s %s: %s", prefix, i.Lang, i.ID, i.Message)
+}
+
+// Check compares every translation in the bundle with the message
+// in the source language. It reports arguments missing or added,
+// arguments used as a different type, select keys missing versus source,
+// and plural categories required by the language's CLDR rules but absent,
+// unless =N cases match all numbers of the category.
+// If provider implements PlaceholderProvider, arguments are compared
+// with declared placeholders of messages too.
+// Bundle provider must implement ListableProvider.
+func Check(b Bundle, source language.Tag) ([]Issue, error) {
+	bb, ok := b.(*bundle)
+	if !ok {
+		return nil, errors.Errorf("unable to check bundle of type %T", b)
+	}
+
+	if _, ok := bb.provider.(ListableProvider); !ok {
+		return nil, errors.New("check requires provider to implement ListableProvider")
+	}
+
+	c := &checker{bundle: bb}
+	sources := map[string]*messageShape{}
+
+	for id := range b.IDs(source) {
+		shape := c.shape(source, id)
+		if shape != nil {
+			sources[id] = shape
+		}
+	}
+
+	for _, lang := range b.Languages() {
+		if lang == source {
+			continue
+		}
+
+		for id := range b.IDs(lang) {
+			shape := c.shape(lang, id)
+			if shape == nil {
+				continue
+			}
+
+			if src, ok := sources[id]; ok {
+				c.compare(lang, id, src, shape)
+			}
+		}
+	}
+
+	return c.issues, nil
+}
+
+type checker struct {
+	bundle *bundle
+	issues []Issue
+}
+
+func (c *checker) report(kind IssueKind, lang language.Tag, id, arg, key, msg string) {
+	issue := Issue{Kind: kind, Lang: lang, ID: id, Arg: arg, Key: key, Message: msg}
+	if sp, ok := c.bundle.provider.(SourceProvider); ok {
+		issue.File, issue.Line, _ = sp.Source(lang, id)
+	}
+
+	c.issues = append(c.issues, issue)
+}
+
+// shape compiles message and checks its plural categories,
+// it returns nil if message is invalid.
+func (c *checker) shape(lang language.Tag, id string) *messageShape {
+	src, err := c.bundle.provider.Get(lang, id)
+	if err != nil {
+		c.report(IssueInvalidMessage, lang, id, "", "", err.Error())
+
+		return nil
+	}
+
+	syntax := messageSyntax(c.bundle.provider, lang, id)
+
+	eval, err := (&translator{buildOptions: c.bundle.buildOptions}).compile(src, syntax, lang)
+	if err != nil {
+		c.report(IssueInvalidMessage, lang, id, "", "", err.Error())
+
+		return nil
+	}
+
+	shape := newMessageShape(message.Args(eval))
+
+	if pp, ok := c.bundle.provider.(PlaceholderProvider); ok {
+		if placeholders := pp.Placeholders(lang, id); placeholders != nil {
+			c.comparePlaceholders(lang, id, shape, placeholders)
+		}
+	}
+
+	// * variants of several selectors are shared by their plurals
+	reported := map[[2]string]bool{}
+
+	for _, p := range shape.plurals {
+		categories := message.PluralCategories(lang)
+		if p.ordinal {
+			categories = message.OrdinalCategories(lang)
+		}
+
+		// =1 is enough for one in English, but not for one in Russian
+		exact := message.ExactCategories(lang, p.ordinal, p.offset, p.exact())
+
+		for _, cat := range categories {
+			if !slices.Contains(p.cases, cat) && !slices.Contains(exact, cat) && !reported[[2]string{p.arg, cat}] {
+				reported[[2]string{p.arg, cat}] = true
+				c.report(IssuePluralCategoryMissing, lang, id, p.arg, cat,
+					fmt.Sprintf("plural {%s} has no case %s", p.arg, cat))
+			}
+		}
+	}
+
+	return shape
+}
+
+func (c *checker) compare(lang language.Tag, id string, src, tr *messageShape) {
+	for _, arg := range slices.Sorted(maps.Keys(src.args)) {
+		kind, ok := tr.args[arg]
+		if !ok {
+			c.report(IssueArgMissing, lang, id, arg, "",
+				fmt.Sprintf("argument {%s} is missing", arg))
+
+			continue
+		}
+
+		srcKind := src.args[arg]
+		if kind != message.ArgString && srcKind != message.ArgString && kind != srcKind {
+			c.report(IssueArgType, lang, id, arg, "",
+				fmt.Sprintf("argument {%s} is used as %s, source uses %s", arg, kind, srcKind))
+		}
+	}
+
+	for _, arg := range slices.Sorted(maps.Keys(tr.args)) {
+		if _, ok := src.args[arg]; !ok {
+			c.report(IssueArgAdded, lang, id, arg, "",
+				fmt.Sprintf("argument {%s} is not in source message", arg))
+		}
+	}
+
+	for _, arg := range slices.Sorted(maps.Keys(src.selects)) {
+		keys, ok := tr.selects[arg]
+		if !ok {
+			continue
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(src.selects[arg])) {
+			if !keys[key] {
+				c.report(IssueSelectKeyMissing, lang, id, arg, key,
+					fmt.Sprintf("select {%s} has no case %s", arg, key))
+			}
+		}
+	}
+}
+
+func (c *checker) comparePlaceholders(lang language.Tag, id string, shape *messageShape, placeholders []Placeholder) {
+	declared := map[string]bool{}
+	for _, p := range placeholders {
+		declared[p.Name] = true
+
+		kind, ok := shape.args[p.Name]
+		if !ok {
+			c.report(IssueArgMissing, lang, id, p.Name, "",
+				fmt.Sprintf("placeholder {%s} is not used", p.Name))
+
+			continue
+		}
+
+		if want, ok := placeholderKinds[p.Type]; ok && kind != message.ArgString && kind != want {
+			c.report(IssueArgType, lang, id, p.Name, "",
+				fmt.Sprintf("argument {%s} is used as %s, placeholder type is %s", p.Name, kind, p.Type))
+		}
+	}
+
+	for _, arg := range slices.Sorted(maps.Keys(shape.args)) {
+		if !declared[arg] {
+			c.report(IssueArgAdded, lang, id, arg, "",
+				fmt.Sprintf("argument {%s} is not declared in placeholders", arg))
+		}
+	}
+}
+
+// placeholderKinds are argument kinds of placeholder types, Object or unknown types match any kind.
+var placeholderKinds = map[string]message.ArgKind{
+	"String":   message.ArgSelectKey,
+	"int":      message.ArgNumber,
+	"double":   message.ArgNumber,
+	"num":      message.ArgNumber,
+	"DateTime": message.ArgTime,
+}
+
+type pluralShape struct {
+	arg     string
+	ordinal bool
+	offset  int
+	cases   []string
+}
+
+// exact returns numbers of =N cases.
+func (p pluralShape) exact() []uint64 {
+	var nums []uint64
+	for _, c := range p.cases {
+		num, ok := strings.CutPrefix(c, "=")
+		if !ok {
+			continue
+		}
+
+		if n, err := strconv.ParseUint(num, 10, 64); err == nil {
+			nums = append(nums, n)
+		}
+	}
+
+	return nums
+}
+
+// messageShape is what Check compares: arguments, select keys and plural cases.
+type messageShape struct {
+	args    map[string]message.ArgKind
+	selects map[string]map[string]bool
+	plurals []pluralShape
+}
+
+func newMessageShape(args []message.ArgInfo) *messageShape {
+	s := &messageShape{
+		args:    map[string]message.ArgKind{},
+		selects: map[string]map[string]bool{},
+	}
+
+	for _, arg := range args {
+		// plain {arg} is compatible with any other kind
+		if current, ok := s.args[arg.Name]; !ok || current == message.ArgString {
+			s.args[arg.Name] = arg.Kind
+		}
+
+		switch {
+		case arg.Kind == message.ArgSelectKey:
+			if s.selects[arg.Name] == nil {
+				s.selects[arg.Name] = map[string]bool{}
+			}
+			for _, key := range arg.Cases {
+				s.selects[arg.Name][key] = true
+			}
+		case arg.Kind == message.ArgNumber && arg.Cases != nil:
+			s.plurals = append(s.plurals, pluralShape{
+				arg:     arg.Name,
+				ordinal: arg.Ordinal,
+				offset:  arg.Offset,
+				cases:   arg.Cases,
+			})
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,137 @@
+package mf
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestCheck(t *testing.T) {
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte(`
+hello: Hello {name}!
+renamed: Hello {name}!
+added: Hello!
+typed: "{count, number}"
+gender: "{gender, select, male {He} female {She} other {They}}"
+apples: "{count, plural, one {# apple} other {# apples}}"
+only_en: Hello
+`)},
+			"messages.ru.yaml": {Data: []byte(`
+hello: Привет {name}!
+renamed: Привет {imya}!
+added: Привет {name}!
+typed: "{count, date, short}"
+gender: "{gender, select, male {Он} other {Они}}"
+apples: "{count, plural, one {# яблоко} few {# яблока} other {# яблок}}"
+broken: "{name"
+`)},
+		}),
+	)
+	require.NoError(t, err)
+
+	issues, err := Check(b, language.English)
+	require.NoError(t, err)
+
+	type issue struct {
+		Kind IssueKind
+		ID   string
+		Arg  string
+		Key  string
+		Line int
+	}
+
+	got := make([]issue, len(issues))
+	for i, is := range issues {
+		assert.Equal(t, language.Russian, is.Lang)
+		assert.Equal(t, "messages.ru.yaml", is.File)
+		got[i] = issue{is.Kind, is.ID, is.Arg, is.Key, is.Line}
+	}
+
+	assert.Equal(t, []issue{
+		{IssueArgAdded, "added", "name", "", 4},
+		{IssuePluralCategoryMissing, "apples", "count", "many", 7},
+		{IssueInvalidMessage, "broken", "", "", 8},
+		{IssueSelectKeyMissing, "gender", "gender", "female", 6},
+		{IssueArgMissing, "renamed", "name", "", 3},
+		{IssueArgAdded, "renamed", "imya", "", 3},
+		{IssueArgType, "typed", "count", "", 5},
+	}, got)
+
+	assert.Equal(t, "messages.ru.yaml:5: ru typed: argument {count} is used as time, source uses number", issues[6].String())
+}
+
+func TestCheck_NotListable(t *testing.T) {
+	b, err := NewBundle(WithProvider(onlyProvider{}))
+	require.NoError(t, err)
+
+	_, err = Check(b, language.English)
+	require.Error(t, err)
+}
+
+func TestCheck_ExactCases(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		msg  string
+		want []string
+	}{
+		{"en =1 is one", "en", "{n, plural, =1 {one} other {#}}", nil},
+		{"en =1 after offset", "en", "{n, plural, offset:1 =2 {one} other {#}}", nil},
+		{"en =0 is not one", "en", "{n, plural, =0 {none} other {#}}", []string{"one"}},
+		{"en ordinal", "en", "{n, selectordinal, =1 {1st} =2 {2nd} =3 {3rd} other {#th}}", []string{"one", "two", "few"}},
+		{"ru one is also 21", "ru", "{n, plural, =1 {одно} few {#} many {#} other {#}}", []string{"one"}},
+		{"fr one has decimals", "fr", "{n, plural, =0 {zéro} =1 {un} many {#} other {#}}", []string{"one"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBundle(WithYamlProvider(fstest.MapFS{
+				"messages." + tt.lang + ".yaml": {Data: []byte("msg: \"" + tt.msg + "\"\n")},
+			}))
+			require.NoError(t, err)
+
+			issues, err := Check(b, language.MustParse(tt.lang))
+			require.NoError(t, err)
+
+			var got []string
+			for _, is := range issues {
+				assert.Equal(t, IssuePluralCategoryMissing, is.Kind)
+				got = append(got, is.Key)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheck_MultiSelector(t *testing.T) {
+	b, err := NewBundle(WithYamlProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+apples: "{gender, count, select plural, female one {She has # apple} female other {She has # apples} other other {They have #}}"
+`)},
+		"messages.ru.yaml": {Data: []byte(`
+apples: "{gender, count, select plural, female one {У неё # яблоко} female few {У неё # яблока} female other {У неё # яблок} other other {У них #}}"
+`)},
+	}))
+	require.NoError(t, err)
+
+	issues, err := Check(b, language.English)
+	require.NoError(t, err)
+
+	var got []string
+	for _, is := range issues {
+		got = append(got, is.String())
+	}
+
+	assert.Equal(t, []string{
+		"messages.en.yaml:2: en apples: plural {count} has no case one",
+		"messages.ru.yaml:2: ru apples: plural {count} has no case many",
+		"messages.ru.yaml:2: ru apples: plural {count} has no case one",
+		"messages.ru.yaml:2: ru apples: plural {count} has no case few",
+	}, got)
+}
@@ -0,0 +1,65 @@
+package mf
+
+import (
+	"context"
+	"time"
+
+	"github.com/fullpipe/icu-mf/message"
+	"golang.org/x/text/currency"
+)
+
+type (
+	translatorKey struct{}
+	timezoneKey   struct{}
+	currencyKey   struct{}
+)
+
+// WithTranslator returns a copy of ctx with translator,
+// use FromContext or T to get it back.
+func WithTranslator(ctx context.Context, tr Translator) context.Context {
+	return context.WithValue(ctx, translatorKey{}, tr)
+}
+
+// FromContext returns translator stored with WithTranslator.
+func FromContext(ctx context.Context) (Translator, bool) {
+	tr, ok := ctx.Value(translatorKey{}).(Translator)
+
+	return tr, ok
+}
+
+// T translates message with translator from the context.
+// If there is no translator in the context, id is returned.
+func T(ctx context.Context, id string, args ...TranslationArg) string {
+	tr, ok := FromContext(ctx)
+	if !ok {
+		return id
+	}
+
+	return tr.TransCtx(ctx, id, args...)
+}
+
+// WithTimezone sets timezone for date and time arguments translated with TransCtx.
+func WithTimezone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, timezoneKey{}, loc)
+}
+
+// WithCurrency sets currency for {amount, number, currency} arguments translated with TransCtx.
+// Without it, the default currency of the language region is used.
+func WithCurrency(ctx context.Context, cur currency.Unit) context.Context {
+	return context.WithValue(ctx, currencyKey{}, cur)
+}
+
+// messageEnv passes context and request-scoped options to message evaluation.
+func messageEnv(ctx context.Context) message.Env {
+	env := message.Env{Context: ctx}
+
+	if loc, ok := ctx.Value(timezoneKey{}).(*time.Location); ok {
+		env.Location = loc
+	}
+
+	if cur, ok := ctx.Value(currencyKey{}).(currency.Unit); ok {
+		env.Currency = cur
+	}
+
+	return env
+}
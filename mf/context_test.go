@@ -0,0 +1,114 @@
+package mf
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	tr := &translator{lang: language.English}
+	got, ok := FromContext(WithTranslator(context.Background(), tr))
+	assert.True(t, ok)
+	assert.Same(t, tr, got)
+}
+
+func TestT(t *testing.T) {
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte(`
+hello: Hello {name}!
+start: Start at {st, time, short}
+price: Price {amount, number, currency}
+`)},
+		}),
+		WithDefaultLangFallback(language.English),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.Equal(t, "hello", T(ctx, "hello", Arg("name", "Bob")), "no translator in context")
+
+	ctx = WithTranslator(ctx, b.Translator("en"))
+	assert.Equal(t, "Hello Bob!", T(ctx, "hello", Arg("name", "Bob")))
+
+	st := time.Date(1961, 4, 12, 6, 7, 3, 0, time.UTC)
+	assert.Equal(t, "Start at 6:07 AM", T(ctx, "start", Time("st", st)))
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	assert.Equal(t, "Start at 9:07 AM", T(WithTimezone(ctx, moscow), "start", Time("st", st)))
+
+	assert.Equal(t, "Price $ 12.50", T(ctx, "price", Arg("amount", 12.5)), "default currency")
+	assert.Equal(t, "Price € 12.50", T(WithCurrency(ctx, currency.EUR), "price", Arg("amount", 12.5)))
+}
+
+func Test_translator_TransCtx(t *testing.T) {
+	var handledErr error
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: Hello!")},
+		}),
+		WithErrorHandler(func(err error, _ string, _ map[string]any) {
+			handledErr = err
+		}),
+	)
+	require.NoError(t, err)
+
+	tr := b.Translator("en")
+	assert.Equal(t, "Hello!", tr.TransCtx(context.Background(), "hello"))
+	require.NoError(t, handledErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, "hello", tr.TransCtx(ctx, "hello"))
+	require.ErrorIs(t, handledErr, context.Canceled)
+}
+
+// cancelOnString cancels the context once the argument is evaluated.
+type cancelOnString struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelOnString) String() string {
+	c.cancel()
+
+	return "a"
+}
+
+func Test_translator_TransCtx_CanceledDuringEval(t *testing.T) {
+	var (
+		handledErr  error
+		handledArgs map[string]any
+	)
+
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: \"{a} and {b}\"")},
+		}),
+		WithErrorHandler(func(err error, _ string, args map[string]any) {
+			handledErr, handledArgs = err, args
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithTimezone(ctx, time.UTC)
+	ctx = WithCurrency(ctx, currency.EUR)
+	arg := cancelOnString{cancel: cancel}
+
+	assert.Equal(t, "hello", b.Translator("en").TransCtx(ctx, "hello", Stringer("a", arg), Arg("b", "b")))
+	require.ErrorIs(t, handledErr, context.Canceled)
+	assert.ElementsMatch(t, []string{"a", "b"}, slices.Collect(maps.Keys(handledArgs)), "only arguments are passed to the handler")
+}
@@ -1,8 +1,12 @@
 package mf
 
 import (
-	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
 
+	"github.com/pkg/errors"
 	y3 "gopkg.in/yaml.v3"
 )
 
@@ -13,12 +17,14 @@ type Dictionary interface {
 type DummyDictionary struct{}
 
 func (*DummyDictionary) Get(id string) (string, error) {
-	return "", fmt.Errorf("no message with id %s", id)
+	return "", errors.Wrapf(ErrMessageNotFound, "no message with id %s", id)
 }
 
 func NewYamlDictionary(yaml []byte) (*YamlDictionary, error) {
 	d := &YamlDictionary{
-		flatMap: make(map[string]string),
+		flatDictionary: newFlatDictionary(),
+		syntaxes:       make(map[string]Syntax),
+		descriptions:   make(map[string]string),
 	}
 
 	var document y3.Node
@@ -27,38 +33,123 @@ func NewYamlDictionary(yaml []byte) (*YamlDictionary, error) {
 	}
 
 	if len(document.Content) > 0 {
-		d.buildFlatMap("", document.Content[0])
+		root := document.Content[0]
+		d.buildFlatMap("", root, syntaxTag(root.Tag, SyntaxICU))
 	}
 
 	return d, nil
 }
 
 type YamlDictionary struct {
+	flatDictionary
+	// syntaxes of messages tagged with !mf2
+	syntaxes map[string]Syntax
+	// descriptions are comments of message keys
+	descriptions map[string]string
+}
+
+// flatDictionary maps message ids, with nested keys joined with dots, to messages.
+type flatDictionary struct {
 	flatMap map[string]string
+	// lines of messages in the file
+	lines map[string]int
+	// file dictionary is loaded from, if any
+	file string
+}
+
+func newFlatDictionary() flatDictionary {
+	return flatDictionary{
+		flatMap: make(map[string]string),
+		lines:   make(map[string]int),
+	}
 }
 
-func (d *YamlDictionary) Get(id string) (string, error) {
+func (d *flatDictionary) Get(id string) (string, error) {
 	if msg, ok := d.flatMap[id]; ok {
 		return msg, nil
 	}
 
-	return "", fmt.Errorf("no message with id %s", id)
+	return "", errors.Wrapf(ErrMessageNotFound, "no message with id %s", id)
+}
+
+// Line returns line of the message in the file, or 0 if there is no such message.
+func (d *flatDictionary) Line(id string) int {
+	return d.lines[id]
+}
+
+// IDs returns sorted ids of all messages.
+func (d *flatDictionary) IDs() iter.Seq[string] {
+	ids := slices.Collect(maps.Keys(d.flatMap))
+	slices.Sort(ids)
+
+	return slices.Values(ids)
+}
+
+func (d *flatDictionary) filename() string {
+	return d.file
 }
 
-func (d *YamlDictionary) buildFlatMap(prefix string, yn *y3.Node) {
+// Syntax returns syntax of the message. Messages are ICU unless
+// they or any of their parent mappings are tagged with !mf2,
+// !icu tag switches back to ICU.
+func (d *YamlDictionary) Syntax(id string) Syntax {
+	return d.syntaxes[id]
+}
+
+// Description returns comments above the message key or after the message, without #.
+func (d *YamlDictionary) Description(id string) string {
+	return d.descriptions[id]
+}
+
+func (d *YamlDictionary) buildFlatMap(prefix string, yn *y3.Node, syntax Syntax) {
 	for i := 0; i < len(yn.Content); i += 2 {
 		keyNode := yn.Content[i]
 		valueNode := yn.Content[i+1]
 
 		key := prefix + keyNode.Value
+		syntax := syntaxTag(valueNode.Tag, syntax)
 
 		switch valueNode.Kind {
 		case y3.ScalarNode:
 			d.flatMap[key] = valueNode.Value
+			d.lines[key] = keyNode.Line
+			if syntax != SyntaxICU {
+				d.syntaxes[key] = syntax
+			}
+
+			if desc := yamlComment(keyNode.HeadComment, keyNode.LineComment, valueNode.LineComment); desc != "" {
+				d.descriptions[key] = desc
+			}
 		case y3.MappingNode:
-			d.buildFlatMap(key+".", valueNode)
+			d.buildFlatMap(key+".", valueNode, syntax)
 		case y3.DocumentNode, y3.SequenceNode, y3.AliasNode:
 			// Ignore other node types
 		}
 	}
 }
+
+// syntaxTag returns syntax set by !mf2 or !icu tag, or the inherited one.
+func syntaxTag(tag string, inherited Syntax) Syntax {
+	switch tag {
+	case "!mf2":
+		return SyntaxMF2
+	case "!icu":
+		return SyntaxICU
+	default:
+		return inherited
+	}
+}
+
+// yamlComment joins lines of comments without #.
+func yamlComment(comments ...string) string {
+	var lines []string
+	for _, c := range comments {
+		for _, line := range strings.Split(c, "\n") {
+			if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#")); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
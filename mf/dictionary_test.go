@@ -1,6 +1,7 @@
 package mf
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,3 +114,49 @@ foo:
 		})
 	}
 }
+
+func TestYamlDictionary_IDs(t *testing.T) {
+	d, err := NewYamlDictionary([]byte("foo: bar\none:\n  two: msg1-2\n  three: msg1-3\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo", "one.three", "one.two"}, slices.Collect(d.IDs()))
+}
+
+func TestYamlDictionary_Syntax(t *testing.T) {
+	d, err := NewYamlDictionary([]byte(`
+icu: "{name}"
+one: !mf2 "{$name}"
+mf2: !mf2
+  two: "{$name}"
+  back: !icu "{name}"
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, SyntaxICU, d.Syntax("icu"))
+	assert.Equal(t, SyntaxMF2, d.Syntax("one"))
+	assert.Equal(t, SyntaxMF2, d.Syntax("mf2.two"))
+	assert.Equal(t, SyntaxICU, d.Syntax("mf2.back"))
+	assert.Equal(t, SyntaxICU, d.Syntax("nope"))
+
+	d, err = NewYamlDictionary([]byte("!mf2\nfoo: \"{$name}\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, SyntaxMF2, d.Syntax("foo"), "file tagged with !mf2")
+}
+
+func TestYamlDictionary_Description(t *testing.T) {
+	d, err := NewYamlDictionary([]byte(`
+# Page title
+title: Shop
+cart:
+  # Items in the cart,
+  # num is a number
+  items: "{num, plural, other {# items}}"
+  empty: Cart is empty # shown without items
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Page title", d.Description("title"))
+	assert.Equal(t, "Items in the cart,\nnum is a number", d.Description("cart.items"))
+	assert.Equal(t, "shown without items", d.Description("cart.empty"))
+	assert.Empty(t, d.Description("nope"))
+}
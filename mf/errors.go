@@ -0,0 +1,135 @@
+package mf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/message"
+	"github.com/fullpipe/icu-mf/parse"
+	"golang.org/x/text/language"
+)
+
+// ErrMessageNotFound is returned by providers and dictionaries
+// when there is no message with the requested id.
+var ErrMessageNotFound = errors.New("message not found")
+
+// Errors from the message and parse packages, so it is enough to import mf
+// to check them with errors.Is and errors.As.
+var (
+	ErrArgumentMissing     = message.ErrArgumentMissing
+	ErrArgumentType        = message.ErrArgumentType
+	ErrUnsupportedFunction = message.ErrUnsupportedFunction
+)
+
+type (
+	ArgumentError = message.ArgumentError
+	SyntaxError   = parse.SyntaxError
+)
+
+// FallbackCycleError is returned by NewBundle if fallbacks are cyclic.
+type FallbackCycleError struct {
+	// Path of the cycle, the first and the last tags are the same.
+	Path []language.Tag
+}
+
+func (e *FallbackCycleError) Error() string {
+	path := make([]string, len(e.Path))
+	for i, tag := range e.Path {
+		path[i] = tag.String()
+	}
+
+	return "cyclic fallback detected: " + strings.Join(path, " -> ")
+}
+
+// TranslationError is passed to ErrorHandler when translation fails.
+type TranslationError struct {
+	// Lang is the language of the translator.
+	Lang language.Tag
+	// MessageLang is the language of the failed message, one of fallbacks
+	// of Lang if the message was found there, or Lang if it was not found.
+	MessageLang language.Tag
+	ID          string
+	// Arg is the name of the failed argument, if any.
+	Arg string
+	Err error
+}
+
+func newTranslationError(lang, messageLang language.Tag, id string, err error) *TranslationError {
+	te := &TranslationError{Lang: lang, MessageLang: messageLang, ID: id, Err: err}
+
+	var argErr *ArgumentError
+	if errors.As(err, &argErr) {
+		te.Arg = argErr.Arg
+	}
+
+	return te
+}
+
+func (e *TranslationError) Error() string {
+	if e.MessageLang != e.Lang {
+		return fmt.Sprintf("unable to translate %s for %s, message in %s: %v", e.ID, e.Lang, e.MessageLang, e.Err)
+	}
+
+	return fmt.Sprintf("unable to translate %s for %s: %v", e.ID, e.Lang, e.Err)
+}
+
+func (e *TranslationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists all invalid messages found by WithStrictValidation.
+type ValidationError struct {
+	Errors []*MessageError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("%d invalid messages:", len(e.Errors)))
+
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// MessageError is a problem with a single message.
+// Position inside the message, if any, is in *SyntaxError.
+type MessageError struct {
+	// File and Line where message is defined, if provider implements SourceProvider.
+	File string
+	Line int
+	Lang language.Tag
+	ID   string
+	Err  error
+}
+
+func (e *MessageError) Error() string {
+	var b strings.Builder
+
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, ":%d", e.Line)
+		}
+		b.WriteString(": ")
+	}
+
+	fmt.Fprintf(&b, "%s %s: %v", e.Lang, e.ID, e.Err)
+
+	return b.String()
+}
+
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,91 @@
+package mf
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestTranslationError(t *testing.T) {
+	var handledErr error
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte(`
+hello: Hello {name}!
+age: "{age, number, integer}"
+broken: "{name"
+spell: "{num, spellout}"
+`)},
+		}),
+		WithDefaultLangFallback(language.English),
+		WithErrorHandler(func(err error, _ string, _ map[string]any) {
+			handledErr = err
+		}),
+	)
+	require.NoError(t, err)
+
+	tr := b.Translator("en")
+
+	tests := []struct {
+		id     string
+		args   []TranslationArg
+		target error
+		arg    string
+	}{
+		{"nope", nil, ErrMessageNotFound, ""},
+		{"hello", nil, ErrArgumentMissing, "name"},
+		{"age", []TranslationArg{Arg("age", "old")}, ErrArgumentType, "age"},
+		{"spell", []TranslationArg{Arg("num", 1)}, ErrUnsupportedFunction, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			handledErr = nil
+			assert.Equal(t, tt.id, tr.Trans(tt.id, tt.args...))
+			require.ErrorIs(t, handledErr, tt.target)
+
+			var trErr *TranslationError
+			require.ErrorAs(t, handledErr, &trErr)
+			assert.Equal(t, language.English, trErr.Lang)
+			assert.Equal(t, tt.id, trErr.ID)
+			assert.Equal(t, tt.arg, trErr.Arg)
+		})
+	}
+
+	handledErr = nil
+	tr.Trans("broken")
+	var syntaxErr *SyntaxError
+	require.ErrorAs(t, handledErr, &syntaxErr)
+	assert.Equal(t, 1, syntaxErr.Pos.Line)
+	assert.False(t, errors.Is(handledErr, ErrMessageNotFound))
+}
+
+func TestTranslationError_Fallback(t *testing.T) {
+	b, err := NewBundle(
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: Hello {name}!")},
+			"messages.es.yaml": {Data: []byte("bye: Adiós")},
+		}),
+		WithLangFallback(language.Catalan, language.Spanish),
+		WithDefaultLangFallback(language.English),
+	)
+	require.NoError(t, err)
+
+	_, err = b.Translator("ca").TransE("hello")
+	require.ErrorIs(t, err, ErrArgumentMissing)
+
+	var trErr *TranslationError
+	require.ErrorAs(t, err, &trErr)
+	assert.Equal(t, language.Catalan, trErr.Lang)
+	assert.Equal(t, language.English, trErr.MessageLang)
+	assert.Equal(t, "name", trErr.Arg)
+	assert.Equal(t, "unable to translate hello for ca, message in en: missing argument name", err.Error())
+
+	_, err = b.Translator("ca").TransE("nope")
+	require.ErrorAs(t, err, &trErr)
+	assert.Equal(t, language.Catalan, trErr.Lang)
+	assert.Equal(t, language.Catalan, trErr.MessageLang)
+}
@@ -0,0 +1,77 @@
+package mf
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+	y3 "gopkg.in/yaml.v3"
+)
+
+// WriteYaml writes messages of the provider for the language as a yaml file
+// for YamlMessageProvider, e.g. to import translations from XLIFF or PO files.
+// Dotted ids become nested keys, descriptions become comments of keys
+// and MF2 messages are tagged with !mf2.
+func WriteYaml(w io.Writer, p ListableProvider, lang language.Tag) error {
+	root := &y3.Node{Kind: y3.MappingNode}
+	keys := map[*y3.Node]map[string]*y3.Node{}
+
+	descriptions, _ := p.(DescriptionProvider)
+	// sorted ids put messages before nested ones, like a before a.b
+	for _, id := range slices.Sorted(p.IDs(lang)) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		key := &y3.Node{Kind: y3.ScalarNode, Tag: "!!str"}
+		value := &y3.Node{Kind: y3.ScalarNode, Tag: "!!str", Value: msg}
+		if messageSyntax(p, lang, id) == SyntaxMF2 {
+			value.Tag = "!mf2"
+		}
+
+		if descriptions != nil {
+			if desc := descriptions.Description(lang, id); desc != "" {
+				key.HeadComment = "# " + strings.ReplaceAll(desc, "\n", "\n# ")
+			}
+		}
+
+		// the rest of id stays dotted, if its prefix is a message already
+		parent, parts := root, strings.Split(id, ".")
+		for len(parts) > 1 {
+			child, ok := keys[parent][parts[0]]
+			if ok && child.Kind != y3.MappingNode {
+				break
+			}
+
+			if !ok {
+				child = &y3.Node{Kind: y3.MappingNode}
+				yamlAdd(keys, parent, parts[0], &y3.Node{Kind: y3.ScalarNode, Tag: "!!str", Value: parts[0]}, child)
+			}
+
+			parent, parts = child, parts[1:]
+		}
+
+		key.Value = strings.Join(parts, ".")
+		yamlAdd(keys, parent, key.Value, key, value)
+	}
+
+	enc := y3.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// yamlAdd adds the key to the mapping.
+func yamlAdd(keys map[*y3.Node]map[string]*y3.Node, mapping *y3.Node, name string, key, value *y3.Node) {
+	if keys[mapping] == nil {
+		keys[mapping] = map[string]*y3.Node{}
+	}
+
+	keys[mapping][name] = value
+	mapping.Content = append(mapping.Content, key, value)
+}
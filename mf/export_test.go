@@ -0,0 +1,57 @@
+package mf
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestWriteYaml(t *testing.T) {
+	p := testXliffProvider(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteYaml(&buf, p, language.English))
+	assert.Equal(t, `cart:
+  # Items in the cart
+  items: '{num, plural, one {# item} other {# items}}'
+hello: Hello, {name} & {name}!
+mf2: !mf2 '{$x}'
+to do: Don't <b>
+`, buf.String())
+
+	// translations from XLIFF back to yaml
+	x, err := NewXliffMessageProvider(fstest.MapFS{"messages.de.xlf": {Data: []byte(testXliff12)}})
+	require.NoError(t, err)
+
+	buf.Reset()
+	require.NoError(t, WriteYaml(&buf, x, language.German))
+	assert.Equal(t, `cart:
+  # Items in the cart
+  items: Sie haben {num, plural, one {# Artikel} other {# Artikel}}
+quote: It's '{gratis}' & {name}
+`, buf.String())
+
+	y, err := NewYamlMessageProvider(fstest.MapFS{"messages.de.yaml": {Data: buf.Bytes()}})
+	require.NoError(t, err)
+	msg, err := y.Get(language.German, "quote")
+	require.NoError(t, err)
+	assert.Equal(t, "It's '{gratis}' & {name}", msg)
+
+	d := &YamlMessageProvider{dictionaries: map[language.Tag]*YamlDictionary{language.English: {flatDictionary: newFlatDictionary()}}}
+	d.dictionaries[language.English].flatMap = map[string]string{"a": "A", "a.b": "AB", "c.d": "CD", "c.e.f": "CEF", "true": "yes"}
+
+	buf.Reset()
+	require.NoError(t, WriteYaml(&buf, d, language.English))
+	assert.Equal(t, `a: A
+a.b: AB
+c:
+  d: CD
+  e:
+    f: CEF
+"true": yes
+`, buf.String())
+}
@@ -0,0 +1,86 @@
+// Package httpmf provides net/http middleware that negotiates user language
+// and puts mf.Translator into the request context.
+package httpmf
+
+import (
+	"net/http"
+
+	"github.com/fullpipe/icu-mf/mf"
+	"golang.org/x/text/language"
+)
+
+type Option func(m *middleware)
+
+type middleware struct {
+	bundle    mf.Bundle
+	supported []language.Tag
+	matcher   language.Matcher
+	sources   []Source
+}
+
+// Middleware picks the best of supported languages for a request and puts
+// translator for it to the request context, use mf.T or mf.FromContext to get it.
+// The first supported language is used if none of sources match.
+// Responses vary by Accept-Language and Cookie headers, as language
+// could be taken from them, so shared caches keep a copy per language.
+//
+// By default sources are checked in order:
+// PathPrefix(), Query("lang"), Cookie("lang") and AcceptLanguage().
+func Middleware(bundle mf.Bundle, supported []language.Tag, options ...Option) func(http.Handler) http.Handler {
+	m := &middleware{
+		bundle:    bundle,
+		supported: supported,
+		matcher:   language.NewMatcher(supported),
+		sources: []Source{
+			PathPrefix(),
+			Query("lang"),
+			Cookie("lang"),
+			AcceptLanguage(),
+		},
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang := m.negotiate(r)
+
+			w.Header().Set("Content-Language", lang.String())
+			w.Header().Add("Vary", "Accept-Language")
+			w.Header().Add("Vary", "Cookie")
+
+			ctx := mf.WithTranslator(r.Context(), m.bundle.TranslatorFor(lang))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithSources replaces default sources, they are checked in the given order.
+func WithSources(sources ...Source) Option {
+	return func(m *middleware) {
+		m.sources = sources
+	}
+}
+
+func (m *middleware) negotiate(r *http.Request) language.Tag {
+	for _, source := range m.sources {
+		tags := source(r)
+		if len(tags) == 0 {
+			continue
+		}
+
+		_, idx, confidence := m.matcher.Match(tags...)
+		if confidence != language.No {
+			return m.supported[idx]
+		}
+	}
+
+	if len(m.supported) == 0 {
+		return language.Und
+	}
+
+	return m.supported[0]
+}
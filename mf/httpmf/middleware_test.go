@@ -0,0 +1,99 @@
+package httpmf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fullpipe/icu-mf/mf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestMiddleware(t *testing.T) {
+	bundle, err := mf.NewBundle(
+		mf.WithDefaultLangFallback(language.English),
+		mf.WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: Hello!")},
+			"messages.es.yaml": {Data: []byte("hello: ¡Hola!")},
+			"messages.ru.yaml": {Data: []byte("hello: Привет!")},
+		}),
+	)
+	require.NoError(t, err)
+
+	supported := []language.Tag{language.English, language.Spanish, language.Russian}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mf.T(r.Context(), "hello")))
+	})
+
+	tests := []struct {
+		name     string
+		target   string
+		cookie   string
+		header   string
+		want     string
+		wantLang string
+	}{
+		{"default language", "/", "", "", "Hello!", "en"},
+		{"path prefix", "/es/about", "", "", "¡Hola!", "es"},
+		{"path prefix has priority over query", "/es/about?lang=ru", "", "", "¡Hola!", "es"},
+		{"unsupported path prefix", "/de/about?lang=ru", "", "", "Привет!", "ru"},
+		{"not a language in path", "/about?lang=ru", "", "", "Привет!", "ru"},
+		{"query has priority over cookie", "/about?lang=es", "ru", "", "¡Hola!", "es"},
+		{"invalid query", "/about?lang=%24%24", "ru", "", "Привет!", "ru"},
+		{"cookie has priority over header", "/", "ru", "es", "Привет!", "ru"},
+		{"accept language", "/", "", "de, es-MX;q=0.8, en;q=0.5", "¡Hola!", "es"},
+		{"unsupported accept language", "/", "", "de", "Hello!", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set("Accept-Language", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			Middleware(bundle, supported)(handler).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Body.String())
+			assert.Equal(t, tt.wantLang, w.Header().Get("Content-Language"))
+			assert.Equal(t, []string{"Accept-Language", "Cookie"}, w.Header().Values("Vary"))
+		})
+	}
+}
+
+func TestWithSources(t *testing.T) {
+	bundle, err := mf.NewBundle(
+		mf.WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: Hello!")},
+			"messages.es.yaml": {Data: []byte("hello: ¡Hola!")},
+		}),
+	)
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mf.T(r.Context(), "hello")))
+	})
+
+	mw := Middleware(
+		bundle,
+		[]language.Tag{language.English, language.Spanish},
+		WithSources(AcceptLanguage(), Query("locale")),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/es/?lang=es&locale=es", nil)
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+	mw(handler).ServeHTTP(w, r)
+	assert.Equal(t, "Hello!", w.Body.String(), "only accept language is used")
+
+	r = httptest.NewRequest(http.MethodGet, "/es/?lang=es&locale=es", nil)
+	w = httptest.NewRecorder()
+	mw(handler).ServeHTTP(w, r)
+	assert.Equal(t, "¡Hola!", w.Body.String(), "custom query param")
+}
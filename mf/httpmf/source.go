@@ -0,0 +1,64 @@
+package httpmf
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Source extracts user preferred languages from request.
+type Source func(r *http.Request) []language.Tag
+
+// PathPrefix uses the first segment of the path, like /es/about.
+func PathPrefix() Source {
+	return func(r *http.Request) []language.Tag {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+		return parseTags(segment)
+	}
+}
+
+// Query uses query parameter, like /about?lang=es.
+func Query(param string) Source {
+	return func(r *http.Request) []language.Tag {
+		return parseTags(r.URL.Query().Get(param))
+	}
+}
+
+// Cookie uses value of the cookie.
+func Cookie(name string) Source {
+	return func(r *http.Request) []language.Tag {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return nil
+		}
+
+		return parseTags(c.Value)
+	}
+}
+
+// AcceptLanguage uses Accept-Language header.
+func AcceptLanguage() Source {
+	return func(r *http.Request) []language.Tag {
+		tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		if err != nil {
+			return nil
+		}
+
+		return tags
+	}
+}
+
+func parseTags(lang string) []language.Tag {
+	if lang == "" {
+		return nil
+	}
+
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return nil
+	}
+
+	return []language.Tag{tag}
+}
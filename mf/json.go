@@ -0,0 +1,259 @@
+package mf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// JsonMessageProvider loads messages from messages.<lang>.json files.
+type JsonMessageProvider struct {
+	dictionaries[*JsonDictionary]
+}
+
+func NewJsonMessageProvider(dir fs.FS) (*JsonMessageProvider, error) {
+	provider := JsonMessageProvider{
+		dictionaries: map[language.Tag]*JsonDictionary{},
+	}
+
+	err := walkLangFiles(dir, []string{".json"}, func(path string, lang language.Tag) error {
+		return provider.loadMessages(dir, path, lang)
+	})
+
+	return &provider, err
+}
+
+func (p *JsonMessageProvider) loadMessages(rd fs.FS, path string, lang language.Tag) error {
+	data, err := readFile(rd, path)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(path, lang); err != nil {
+		return err
+	}
+
+	d, err := NewJsonDictionary(data)
+	if err != nil {
+		return fileError(path, err)
+	}
+
+	d.file = path
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Description returns description of a message in FormatJS shape, if any.
+func (p *JsonMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
+}
+
+// JsonDictionary is a dictionary of nested JSON objects, like i18next uses:
+//
+//	{"cart": {"items": "{num, plural, one {# item} other {# items}}"}}
+//
+// Nested keys are joined with dots, cart.items here. Objects in FormatJS shape,
+// {"defaultMessage": "...", "description": "..."}, are messages too.
+type JsonDictionary struct {
+	flatDictionary
+	descriptions map[string]string
+}
+
+func NewJsonDictionary(data []byte) (*JsonDictionary, error) {
+	d := &JsonDictionary{
+		flatDictionary: newFlatDictionary(),
+		descriptions:   make(map[string]string),
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return d, nil
+	}
+
+	r := &jsonReader{data: data, dec: json.NewDecoder(bytes.NewReader(data)), line: 1}
+	r.dec.UseNumber()
+
+	t, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+
+	if t != json.Delim('{') {
+		return nil, errors.New("json root must be an object")
+	}
+
+	root, err := r.object()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, &lineError{Line: r.lineAt(r.dec.InputOffset()), Msg: "unexpected data after the root object"}
+	}
+
+	d.buildFlatMap("", root)
+
+	return d, nil
+}
+
+// Description returns description of the message, if any.
+func (d *JsonDictionary) Description(id string) string {
+	return d.descriptions[id]
+}
+
+func (d *JsonDictionary) buildFlatMap(prefix string, entries []jsonEntry) {
+	for _, e := range entries {
+		key := prefix + e.Key
+
+		switch v := e.Value.(type) {
+		case string:
+			d.flatMap[key] = v
+			d.lines[key] = e.Line
+		case []jsonEntry:
+			if msg, desc, ok := formatJSMessage(v); ok {
+				d.flatMap[key] = msg
+				d.lines[key] = e.Line
+				if desc != "" {
+					d.descriptions[key] = desc
+				}
+
+				continue
+			}
+
+			d.buildFlatMap(key+".", v)
+		}
+	}
+}
+
+// formatJSMessage returns message of {"defaultMessage": "...", "description": "..."}.
+func formatJSMessage(entries []jsonEntry) (msg, desc string, ok bool) {
+	for _, e := range entries {
+		switch e.Key {
+		case "defaultMessage":
+			msg, ok = e.Value.(string)
+			if !ok {
+				return "", "", false
+			}
+		case "description":
+			// descriptions could be objects too, they are skipped
+			desc, _ = e.Value.(string)
+		case "id":
+		default:
+			return "", "", false
+		}
+	}
+
+	return msg, desc, ok
+}
+
+// jsonEntry is a key of an object with its value:
+// a string for scalars, []jsonEntry for objects, or nil for null and arrays.
+type jsonEntry struct {
+	Key   string
+	Line  int
+	Value any
+}
+
+// jsonReader reads objects keeping order and lines of keys.
+type jsonReader struct {
+	data []byte
+	dec  *json.Decoder
+	// line at offset, offsets only grow
+	offset int64
+	line   int
+}
+
+func (r *jsonReader) token() (json.Token, error) {
+	t, err := r.dec.Token()
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &lineError{Line: r.lineAt(syntaxErr.Offset), Msg: err.Error()}
+		}
+
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *jsonReader) lineAt(offset int64) int {
+	if offset > r.offset {
+		r.line += bytes.Count(r.data[r.offset:offset], []byte("\n"))
+		r.offset = offset
+	}
+
+	return r.line
+}
+
+// object reads the rest of an object after {.
+func (r *jsonReader) object() ([]jsonEntry, error) {
+	var entries []jsonEntry
+	for {
+		t, err := r.token()
+		if err != nil {
+			return nil, err
+		}
+
+		if t == json.Delim('}') {
+			return entries, nil
+		}
+
+		e := jsonEntry{Key: fmt.Sprint(t), Line: r.lineAt(r.dec.InputOffset())}
+		if e.Value, err = r.value(); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+}
+
+func (r *jsonReader) value() (any, error) {
+	t, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '{' {
+			return r.object()
+		}
+
+		// arrays are skipped
+		for depth := 1; depth > 0; {
+			if t, err = r.token(); err != nil {
+				return nil, err
+			}
+
+			switch t {
+			case json.Delim('['), json.Delim('{'):
+				depth++
+			case json.Delim(']'), json.Delim('}'):
+				depth--
+			}
+		}
+
+		return nil, nil
+	case nil:
+		return nil, nil
+	default:
+		// strings, numbers and booleans are messages, like yaml scalars
+		return fmt.Sprint(v), nil
+	}
+}
+
+var (
+	_ ListableProvider = (*JsonMessageProvider)(nil)
+	_ SourceProvider   = (*JsonMessageProvider)(nil)
+)
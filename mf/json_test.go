@@ -0,0 +1,96 @@
+package mf
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestNewJsonDictionary(t *testing.T) {
+	d, err := NewJsonDictionary([]byte(`{
+  "title": "Hello",
+  "cart": {
+    "items": "{num, plural, one {# item} other {# items}}",
+    "empty": {"defaultMessage": "Cart is empty", "description": "Shown without items"}
+  },
+  "count": 42,
+  "enabled": true,
+  "list": ["a", {"b": "c"}],
+  "none": null,
+  "formatjs": {"defaultMessage": "Hi", "description": {"context": "greeting"}, "id": "formatjs"},
+  "nested": {"defaultMessage": "not a message", "other": "key"}
+}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"cart.empty",
+		"cart.items",
+		"count",
+		"enabled",
+		"formatjs",
+		"nested.defaultMessage",
+		"nested.other",
+		"title",
+	}, slices.Collect(d.IDs()))
+
+	msg, err := d.Get("cart.empty")
+	require.NoError(t, err)
+	assert.Equal(t, "Cart is empty", msg)
+	assert.Equal(t, "Shown without items", d.Description("cart.empty"))
+	assert.Empty(t, d.Description("formatjs"))
+
+	msg, err = d.Get("count")
+	require.NoError(t, err)
+	assert.Equal(t, "42", msg)
+
+	_, err = d.Get("cart")
+	require.ErrorIs(t, err, ErrMessageNotFound)
+
+	assert.Equal(t, 2, d.Line("title"))
+	assert.Equal(t, 4, d.Line("cart.items"))
+	assert.Equal(t, 5, d.Line("cart.empty"))
+	assert.Equal(t, 0, d.Line("cart"))
+
+	d, err = NewJsonDictionary([]byte(" \n"))
+	require.NoError(t, err, "empty file")
+	assert.Empty(t, slices.Collect(d.IDs()))
+
+	for _, in := range []string{`[]`, `"foo"`, `{"foo": "bar"`, `{"foo": "bar"} {}`, "{\n\"foo\": bar}"} {
+		_, err := NewJsonDictionary([]byte(in))
+		assert.Error(t, err, in)
+	}
+
+	_, err = NewJsonDictionary([]byte("{\n\"foo\": bar}"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestNewJsonMessageProvider(t *testing.T) {
+	p, err := NewJsonMessageProvider(fstest.MapFS{
+		"messages.en.json":    {Data: []byte(`{"foo": {"bar": "baz"}}`)},
+		"messages.es.json":    {Data: []byte(`{"foo": {"bar": {"defaultMessage": "es", "description": "d"}}}`)},
+		"messages.de.yaml":    {Data: []byte("foo: bar")},
+		"messages.ru-RU.json": {Data: []byte(`{}`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.Spanish, language.MustParse("ru-RU")}, p.Languages())
+	assert.Equal(t, "d", p.Description(language.Spanish, "foo.bar"))
+
+	file, line, ok := p.Source(language.English, "foo.bar")
+	assert.True(t, ok)
+	assert.Equal(t, "messages.en.json", file)
+	assert.Equal(t, 1, line)
+
+	_, err = NewJsonMessageProvider(fstest.MapFS{"messages.en.json": {Data: []byte("{\n\"foo\": }")}})
+	require.EqualError(t, err, "messages.en.json:2: missing value after object key")
+
+	b, err := NewBundle(WithJsonProvider(fstest.MapFS{
+		"messages.en.json": {Data: []byte(`{"cart": {"items": "{num, plural, one {# item} other {# items}}"}}`)},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "3 items", b.Translator("en").Trans("cart.items", Arg("num", 3)))
+}
@@ -0,0 +1,77 @@
+package mf
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NewMoDictionary loads compiled gettext MO file, see PoDictionary.
+// MO files have no comments and lines.
+func NewMoDictionary(data []byte) (*PoDictionary, error) {
+	if len(data) < 20 {
+		return nil, errors.New("invalid mo file: too short")
+	}
+
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid mo file: wrong magic number")
+	}
+
+	n := order.Uint32(data[8:])
+	origs, trans := order.Uint32(data[12:]), order.Uint32(data[16:])
+
+	// str returns i-th string of the table at offset
+	str := func(table, i uint32) (string, error) {
+		at := uint64(table) + uint64(i)*8
+		if at+8 > uint64(len(data)) {
+			return "", errors.New("invalid mo file: string table out of range")
+		}
+
+		length, offset := uint64(order.Uint32(data[at:])), uint64(order.Uint32(data[at+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", errors.New("invalid mo file: string out of range")
+		}
+
+		return string(data[offset : offset+length]), nil
+	}
+
+	d := newPoDictionary()
+	for i := range n {
+		orig, err := str(origs, i)
+		if err != nil {
+			return nil, err
+		}
+
+		str, err := str(trans, i)
+		if err != nil {
+			return nil, err
+		}
+
+		e := &PoEntry{ID: orig, Str: str}
+		if ctx, id, ok := strings.Cut(orig, PoContextSeparator); ok {
+			e.Context, e.ID = ctx, id
+		}
+
+		// msgid and msgid_plural are separated with NUL
+		if strings.Contains(e.ID, "\x00") {
+			return nil, errors.Errorf("msgid_plural of %q is not supported, use ICU plural in msgstr", strings.Split(e.ID, "\x00")[0])
+		}
+
+		if _, ok := d.Entry(PoID(e.Context, e.ID)); ok {
+			return nil, errors.Errorf("duplicate message %q", orig)
+		}
+
+		if err := d.add(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
@@ -0,0 +1,508 @@
+package mf
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// MobileOption configures conversion of messages from and to
+// Android strings.xml and iOS .strings and .stringsdict files.
+type MobileOption func(*mobileOptions)
+
+type mobileOptions struct {
+	args            MessageProvider
+	argsLang        language.Tag
+	defaultLang     language.Tag
+	skipUnsupported bool
+}
+
+func newMobileOptions(opts []MobileOption) mobileOptions {
+	var o mobileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// MobileArgsFrom takes names and positions of arguments from the same messages
+// of lang in p, usually the source language. Positions are in order of the first
+// appearance of arguments in the message, so translations keep positions of the source.
+//
+// Without it, exported messages number their own arguments and imported
+// messages name arguments after their positions, like arg1.
+func MobileArgsFrom(p MessageProvider, lang language.Tag) MobileOption {
+	return func(o *mobileOptions) {
+		o.args, o.argsLang = p, lang
+	}
+}
+
+// MobileSkipUnsupported skips messages, which can not be converted, like select
+// or nested plurals, instead of failing.
+func MobileSkipUnsupported() MobileOption {
+	return func(o *mobileOptions) {
+		o.skipUnsupported = true
+	}
+}
+
+// MobileDefaultLang is the language of Android values directory without qualifiers.
+// By default it is skipped.
+func MobileDefaultLang(lang language.Tag) MobileOption {
+	return func(o *mobileOptions) {
+		o.defaultLang = lang
+	}
+}
+
+// reference returns the message of MobileArgsFrom, if any.
+func (o *mobileOptions) reference(id string) *parse.Message {
+	if o.args == nil {
+		return nil
+	}
+
+	ref, err := o.args.Get(o.argsLang, id)
+	if err != nil {
+		return nil
+	}
+
+	m, err := parse.Parse(ref)
+	if err != nil {
+		return nil
+	}
+
+	return m
+}
+
+// argNames returns names of arguments by positions, the first is at 1: arguments
+// of the reference message, then the rest of arguments of m, in order of appearance.
+func (o *mobileOptions) argNames(id string, m *parse.Message) []string {
+	var names []string
+	if ref := o.reference(id); ref != nil {
+		names = appendArgNames(names, ref)
+	}
+
+	if m != nil {
+		names = appendArgNames(names, m)
+	}
+
+	return names
+}
+
+// printfReader returns reader of printf arguments of the message, named after the reference.
+func (o *mobileOptions) printfReader(id string) *printfReader {
+	r := &printfReader{names: o.argNames(id, nil), decimals: map[string]bool{}}
+	if ref := o.reference(id); ref != nil {
+		parse.Inspect(ref, func(n parse.Node) bool {
+			if f, ok := n.(*parse.Func); ok && f.Func == "number" && f.Param == "" {
+				r.decimals[f.ArgName] = true
+			}
+
+			return true
+		})
+	}
+
+	return r
+}
+
+// pluralArg returns name of the top level plural argument of the reference message.
+func (o *mobileOptions) pluralArg(id string) string {
+	if ref := o.reference(id); ref != nil {
+		for _, f := range ref.Fragments {
+			if f.Expr != nil && f.Expr.Func == "plural" {
+				return f.Expr.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// appendArgNames appends names of arguments of the message, which are not in names yet.
+func appendArgNames(names []string, m *parse.Message) []string {
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	parse.Inspect(m, func(n parse.Node) bool {
+		switch n := n.(type) {
+		case *parse.PlainArg:
+			add(n.Name)
+		case *parse.Func:
+			add(n.ArgName)
+		case *parse.Expr:
+			add(n.Name)
+			for _, s := range n.Selectors {
+				add(s.Name)
+			}
+		}
+
+		return true
+	})
+
+	return names
+}
+
+// unsupportedError is an error of a message, which can not be converted.
+type unsupportedError struct {
+	ID     string
+	Reason string
+}
+
+func (e *unsupportedError) Error() string {
+	return e.ID + ": " + e.Reason
+}
+
+// skip reports if the error of the message must be skipped.
+func (o *mobileOptions) skip(err error) bool {
+	var unsupported *unsupportedError
+
+	return o.skipUnsupported && errors.As(err, &unsupported)
+}
+
+// pluralCategories are keys of plural forms in mobile formats.
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// printfWriter writes ICU messages with printf format specifiers of arguments:
+// {name} is %1$s, {n, number, integer} and # are %1$d, {n, number} is %1$f.
+type printfWriter struct {
+	id    string
+	names []string
+	// object is conversion of strings, s on Android and @ on iOS
+	object string
+	// decimal is conversion of {n, number}, f on Android and @ of NSNumber on iOS
+	decimal string
+	// escape escapes text for the file format
+	escape func(string) string
+	// formatted is true for messages with arguments, % of their text are escaped
+	formatted bool
+}
+
+func newPrintfWriter(id string, m *parse.Message, o *mobileOptions, object, decimal string, escape func(string) string) *printfWriter {
+	names := o.argNames(id, m)
+
+	return &printfWriter{id: id, names: names, object: object, decimal: decimal, escape: escape, formatted: len(appendArgNames(nil, m)) > 0}
+}
+
+func (w *printfWriter) unsupported(format string, args ...any) error {
+	return &unsupportedError{ID: w.id, Reason: fmt.Sprintf(format, args...)}
+}
+
+// spec returns format specifier of the argument.
+func (w *printfWriter) spec(name, conversion string) string {
+	for i, n := range w.names {
+		if n == name {
+			return "%" + strconv.Itoa(i+1) + "$" + conversion
+		}
+	}
+
+	// names have all arguments of the message
+	panic("mf: no position of argument " + name)
+}
+
+// write writes fragments, plural is the argument of # or empty outside of plural.
+func (w *printfWriter) write(b *strings.Builder, fragments []*parse.Fragment, plural string) error {
+	for _, f := range fragments {
+		switch {
+		case f.Escaped != "":
+			w.text(b, f.Escaped[1:])
+		case f.PlainArg != nil:
+			b.WriteString(w.spec(f.PlainArg.Name, w.object))
+		case f.Func != nil:
+			if f.Func.Func != "number" || f.Func.Param != "" && f.Func.Param != "integer" {
+				return w.unsupported("{%s, %s} is not supported", f.Func.ArgName, strings.TrimSuffix(f.Func.Func+", "+f.Func.Param, ", "))
+			}
+
+			conversion := w.decimal
+			if f.Func.Param == "integer" {
+				conversion = "d"
+			}
+
+			b.WriteString(w.spec(f.Func.ArgName, conversion))
+		case f.Octothorpe:
+			if plural == "" {
+				w.text(b, "#")
+
+				continue
+			}
+
+			b.WriteString(w.spec(plural, "d"))
+		case f.Expr != nil:
+			return w.unsupported("nested {%s, %s} is not supported", f.Expr.Name, f.Expr.Func)
+		default:
+			w.text(b, f.Text)
+		}
+	}
+
+	return nil
+}
+
+func (w *printfWriter) text(b *strings.Builder, text string) {
+	if w.formatted {
+		text = strings.ReplaceAll(text, "%", "%%")
+	}
+
+	b.WriteString(w.escape(text))
+}
+
+// pluralCases checks that the plural is supported, exact =0 case becomes zero, if allowed.
+func (w *printfWriter) pluralCases(e *parse.Expr, exactZero bool) (map[string]*parse.Message, []string, error) {
+	if e.Func != "plural" || len(e.Selectors) > 0 {
+		return nil, nil, w.unsupported("{%s, %s} is not supported", e.Name, e.Func)
+	}
+
+	if e.Offset != 0 {
+		return nil, nil, w.unsupported("offset of {%s, plural} is not supported", e.Name)
+	}
+
+	cases := map[string]*parse.Message{}
+	var keys []string
+	for _, c := range e.Cases {
+		key := c.Name
+		if key == "=0" && exactZero {
+			key = "zero"
+		}
+
+		if !slices.Contains(pluralCategories, key) {
+			return nil, nil, w.unsupported("case %s of {%s, plural} is not supported", c.Name, e.Name)
+		}
+
+		if _, ok := cases[key]; ok {
+			return nil, nil, w.unsupported("case %s of {%s, plural} duplicates another case", c.Name, e.Name)
+		}
+
+		msg := c.Message
+		if msg == nil {
+			msg = &parse.Message{}
+		}
+
+		cases[key] = msg
+		keys = append(keys, key)
+	}
+
+	return cases, keys, nil
+}
+
+// printfReader converts printf format strings to ICU message segments.
+type printfReader struct {
+	names []string
+	// decimals are {n, number} arguments of the reference, %@ of iOS is NSNumber for them
+	decimals map[string]bool
+	// next is the position of the next specifier without position
+	next int
+}
+
+// name returns the name of the argument at the position.
+func (r *printfReader) name(pos int) string {
+	if pos <= len(r.names) && r.names[pos-1] != "" {
+		return r.names[pos-1]
+	}
+
+	return "arg" + strconv.Itoa(pos)
+}
+
+// setName names the argument at the position, if it has no name yet.
+func (r *printfReader) setName(pos int, name string) {
+	for len(r.names) < pos {
+		r.names = append(r.names, "")
+	}
+
+	if r.names[pos-1] == "" && isArgName(name) {
+		r.names[pos-1] = name
+	}
+}
+
+func isArgName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i := range len(name) {
+		c := name[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segments converts the format string. Specifiers of the plural position
+// with integer conversion are #. If fixed is not 0, specifiers without position
+// refer to it, like in rules of iOS plural variables.
+// variable is called for %#@name@ variables of iOS with their positions.
+func (r *printfReader) segments(s string, plural, fixed int, variable func(name string, pos int) ([]parse.Segment, error)) ([]parse.Segment, error) {
+	formatted := hasPrintfArgs(s)
+
+	var segs []parse.Segment
+	for {
+		i := strings.IndexByte(s, '%')
+		if i < 0 {
+			return append(segs, parse.Segment{Text: s}), nil
+		}
+
+		segs = append(segs, parse.Segment{Text: s[:i]})
+		spec, ok := scanPrintf(s[i:])
+		if !ok {
+			segs = append(segs, parse.Segment{Text: "%"})
+			s = s[i+1:]
+
+			continue
+		}
+
+		s = s[i+len(spec.raw):]
+		if spec.conversion == '%' {
+			if formatted {
+				segs = append(segs, parse.Segment{Text: "%"})
+			} else {
+				segs = append(segs, parse.Segment{Text: "%%"})
+			}
+
+			continue
+		}
+
+		pos := spec.pos
+		switch {
+		case pos == 0 && fixed != 0:
+			pos = fixed
+		case pos == 0:
+			r.next++
+			pos = r.next
+		}
+
+		if spec.variable != "" {
+			if variable == nil {
+				return nil, errors.Errorf("unexpected variable %s", spec.raw)
+			}
+
+			r.setName(pos, spec.variable)
+			vs, err := variable(spec.variable, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			segs = append(segs, vs...)
+
+			continue
+		}
+
+		switch spec.conversion {
+		case 's', 'S', '@':
+			if r.decimals[r.name(pos)] {
+				segs = append(segs, parse.Segment{Code: "{" + r.name(pos) + ", number}"})
+			} else {
+				segs = append(segs, parse.Segment{Code: "{" + r.name(pos) + "}"})
+			}
+		case 'd', 'D', 'i', 'u', 'U':
+			if pos == plural {
+				segs = append(segs, parse.Segment{Code: "#"})
+			} else {
+				segs = append(segs, parse.Segment{Code: "{" + r.name(pos) + ", number, integer}"})
+			}
+		case 'f', 'F', 'e', 'E', 'g', 'G', 'a', 'A':
+			segs = append(segs, parse.Segment{Code: "{" + r.name(pos) + ", number}"})
+		default:
+			return nil, errors.Errorf("format %s is not supported", spec.raw)
+		}
+	}
+}
+
+// printfSpec is a format specifier, like %1$s, %.2f or %#@files@ of iOS.
+type printfSpec struct {
+	raw string
+	// pos is explicit position, or 0
+	pos        int
+	conversion byte
+	variable   string
+}
+
+// scanPrintf scans the specifier at the start of s, flags, width and precision are skipped.
+func scanPrintf(s string) (printfSpec, bool) {
+	i := 1
+	spec := printfSpec{}
+
+	// position
+	if n := span(s[i:], isDigitByte); n > 0 && i+n < len(s) && s[i+n] == '$' {
+		spec.pos, _ = strconv.Atoi(s[i : i+n])
+		if spec.pos == 0 {
+			return spec, false
+		}
+
+		i += n + 1
+	}
+
+	// iOS variable
+	if strings.HasPrefix(s[i:], "#@") {
+		end := strings.IndexByte(s[i+2:], '@')
+		if end <= 0 {
+			return spec, false
+		}
+
+		spec.variable = s[i+2 : i+2+end]
+		spec.raw = s[:i+2+end+1]
+
+		return spec, true
+	}
+
+	// space flag is skipped, so 50% off is not a specifier
+	i += span(s[i:], func(c byte) bool { return strings.IndexByte("-+0#", c) >= 0 })
+	i += span(s[i:], isDigitByte)
+	if i < len(s) && s[i] == '.' {
+		i++
+		i += span(s[i:], isDigitByte)
+	}
+
+	for _, length := range []string{"hh", "ll", "h", "l", "q", "z", "t", "j", "L"} {
+		if strings.HasPrefix(s[i:], length) {
+			i += len(length)
+
+			break
+		}
+	}
+
+	if i >= len(s) || strings.IndexByte("@dDiuUxXoOfFeEgGaAcCsSp%", s[i]) < 0 {
+		return spec, false
+	}
+
+	spec.conversion = s[i]
+	spec.raw = s[:i+1]
+
+	return spec, true
+}
+
+// hasPrintfArgs reports if the string has specifiers of arguments, %% are escaped % only then.
+func hasPrintfArgs(s string) bool {
+	for {
+		i := strings.IndexByte(s, '%')
+		if i < 0 {
+			return false
+		}
+
+		if spec, ok := scanPrintf(s[i:]); ok && spec.conversion != '%' {
+			return true
+		} else if ok {
+			s = s[i+len(spec.raw):]
+		} else {
+			s = s[i+1:]
+		}
+	}
+}
+
+func span(s string, f func(byte) bool) int {
+	n := 0
+	for n < len(s) && f(s[n]) {
+		n++
+	}
+
+	return n
+}
+
+func isDigitByte(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,412 @@
+package mf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// PoContextSeparator joins msgctxt and msgid to a message id, like gettext does.
+const PoContextSeparator = "\x04"
+
+// PoID returns id of the message with the context, msgctxt could be empty.
+func PoID(msgctxt, msgid string) string {
+	if msgctxt == "" {
+		return msgid
+	}
+
+	return msgctxt + PoContextSeparator + msgid
+}
+
+// PoEntry is a translated message of a PO file with its comments.
+type PoEntry struct {
+	Context string
+	ID      string
+	// Str is ICU message
+	Str string
+	// TranslatorComments are # comments
+	TranslatorComments []string
+	// ExtractedComments are #. comments
+	ExtractedComments []string
+	// References are #: file:line references
+	References []string
+	// Flags are #, flags, like fuzzy
+	Flags []string
+	// Line of the entry, 0 for MO files
+	Line int
+}
+
+type PoOption func(*poOptions)
+
+type poOptions struct {
+	fuzzy bool
+}
+
+// PoIncludeFuzzy loads fuzzy entries too, by default they are skipped like msgfmt does.
+func PoIncludeFuzzy() PoOption {
+	return func(o *poOptions) {
+		o.fuzzy = true
+	}
+}
+
+// PoMessageProvider loads messages from messages.<lang>.po files,
+// or compiled messages.<lang>.mo files.
+type PoMessageProvider struct {
+	dictionaries[*PoDictionary]
+	options []PoOption
+}
+
+func NewPoMessageProvider(dir fs.FS, opts ...PoOption) (*PoMessageProvider, error) {
+	provider := PoMessageProvider{
+		dictionaries: map[language.Tag]*PoDictionary{},
+		options:      opts,
+	}
+
+	err := walkLangFiles(dir, []string{".po", ".mo"}, func(path string, lang language.Tag) error {
+		return provider.loadMessages(dir, path, lang)
+	})
+
+	return &provider, err
+}
+
+func (p *PoMessageProvider) loadMessages(rd fs.FS, file string, lang language.Tag) error {
+	data, err := readFile(rd, file)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(file, lang); err != nil {
+		return err
+	}
+
+	var d *PoDictionary
+	if path.Ext(file) == ".mo" {
+		d, err = NewMoDictionary(data)
+	} else {
+		d, err = NewPoDictionary(data, p.options...)
+	}
+
+	if err != nil {
+		return fileError(file, err)
+	}
+
+	d.file = file
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Entry returns the message with its comments.
+func (p *PoMessageProvider) Entry(lang language.Tag, id string) (*PoEntry, bool) {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return nil, false
+	}
+
+	return d.Entry(id)
+}
+
+// Description returns #. comments of the message.
+func (p *PoMessageProvider) Description(lang language.Tag, id string) string {
+	e, ok := p.Entry(lang, id)
+	if !ok {
+		return ""
+	}
+
+	return strings.Join(e.ExtractedComments, "\n")
+}
+
+// PoDictionary is a dictionary of a gettext PO or MO file with ICU messages in msgstr:
+//
+//	#. Items in the cart
+//	#: cart.go:42
+//	msgctxt "cart"
+//	msgid "items"
+//	msgstr "{num, plural, one {# item} other {# items}}"
+//
+// Message id is msgid, or msgctxt and msgid joined with PoContextSeparator, see PoID.
+// Untranslated entries, the header and obsolete entries are skipped.
+// Entries with msgid_plural are errors, use ICU plural in msgstr instead.
+type PoDictionary struct {
+	flatDictionary
+	entries map[string]*PoEntry
+}
+
+func newPoDictionary() *PoDictionary {
+	return &PoDictionary{
+		flatDictionary: newFlatDictionary(),
+		entries:        make(map[string]*PoEntry),
+	}
+}
+
+// Entry returns the message with its comments.
+func (d *PoDictionary) Entry(id string) (*PoEntry, bool) {
+	e, ok := d.entries[id]
+
+	return e, ok
+}
+
+// add adds translated entry, the header and untranslated entries are skipped.
+func (d *PoDictionary) add(e *PoEntry) error {
+	if e.ID == "" && e.Context == "" || e.Str == "" {
+		return nil
+	}
+
+	id := PoID(e.Context, e.ID)
+	if first, ok := d.entries[id]; ok {
+		return &lineError{Line: e.Line, Msg: fmt.Sprintf("duplicate message %q, first defined on line %d", id, first.Line)}
+	}
+
+	d.entries[id] = e
+	d.flatMap[id] = e.Str
+	d.lines[id] = e.Line
+
+	return nil
+}
+
+func NewPoDictionary(data []byte, opts ...PoOption) (*PoDictionary, error) {
+	var o poOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &poParser{d: newPoDictionary(), options: o, entry: &PoEntry{}}
+
+	s := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	s.Buffer(nil, len(data)+1)
+	for s.Scan() {
+		p.line++
+		if err := p.parseLine(strings.TrimSpace(s.Text())); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := p.flush(); err != nil {
+		return nil, err
+	}
+
+	return p.d, nil
+}
+
+type poParser struct {
+	d       *PoDictionary
+	options poOptions
+	line    int
+
+	entry *PoEntry
+	// field is the string continuation lines are appended to
+	field *string
+	// hasID and hasStr are true after msgid and msgstr of the entry
+	hasID, hasStr bool
+	plural        bool
+}
+
+func (p *poParser) errorf(format string, args ...any) error {
+	return &lineError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// flush adds the entry, if it has a msgid, and starts a new one.
+func (p *poParser) flush() error {
+	e, plural, hasID, hasStr := p.entry, p.plural, p.hasID, p.hasStr
+	p.entry, p.field, p.hasID, p.hasStr, p.plural = &PoEntry{}, nil, false, false, false
+
+	switch {
+	case hasID && !hasStr:
+		return &lineError{Line: e.Line, Msg: fmt.Sprintf("msgid %q without msgstr", e.ID)}
+	case !hasStr:
+		// comments without entry
+		return nil
+	}
+
+	if !p.options.fuzzy && slices.Contains(e.Flags, "fuzzy") {
+		return nil
+	}
+
+	if plural {
+		return &lineError{Line: e.Line, Msg: "msgid_plural is not supported, use ICU plural in msgstr"}
+	}
+
+	return p.d.add(e)
+}
+
+func (p *poParser) parseLine(line string) error {
+	switch {
+	case line == "":
+		return p.flush()
+	case strings.HasPrefix(line, "#~"):
+		// obsolete entry
+		return nil
+	case strings.HasPrefix(line, "#"):
+		if p.hasID || p.hasStr {
+			if err := p.flush(); err != nil {
+				return err
+			}
+		}
+
+		p.comment(line)
+		p.field = nil
+
+		return nil
+	case strings.HasPrefix(line, `"`):
+		if p.field == nil {
+			return p.errorf("unexpected string, expected msgid, msgstr or msgctxt before it")
+		}
+
+		s, err := p.unquote(line)
+		if err != nil {
+			return err
+		}
+
+		*p.field += s
+
+		return nil
+	}
+
+	keyword, value, _ := strings.Cut(line, " ")
+	s, err := p.unquote(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+
+	// msgctxt or msgid start the next entry
+	if (keyword == "msgctxt" || keyword == "msgid") && (p.hasID || p.hasStr) {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case keyword == "msgctxt":
+		p.entry.Line = p.line
+		p.entry.Context = s
+		p.field = &p.entry.Context
+	case keyword == "msgid":
+		if p.entry.Line == 0 {
+			p.entry.Line = p.line
+		}
+
+		p.hasID = true
+		p.entry.ID = s
+		p.field = &p.entry.ID
+	case keyword == "msgid_plural":
+		p.plural = true
+		p.field = new(string)
+	case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+		if !p.hasID {
+			return p.errorf("%s without msgid", keyword)
+		}
+
+		p.hasStr = true
+		p.field = new(string)
+		if keyword == "msgstr" {
+			p.entry.Str = s
+			p.field = &p.entry.Str
+		} else {
+			p.plural = true
+		}
+	default:
+		return p.errorf("unexpected keyword %s", keyword)
+	}
+
+	return nil
+}
+
+func (p *poParser) comment(line string) {
+	kind, text := line[:min(2, len(line))], strings.TrimSpace(line[min(2, len(line)):])
+
+	switch kind {
+	case "#.":
+		p.entry.ExtractedComments = append(p.entry.ExtractedComments, text)
+	case "#:":
+		p.entry.References = append(p.entry.References, strings.Fields(text)...)
+	case "#,":
+		for _, flag := range strings.Split(text, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				p.entry.Flags = append(p.entry.Flags, flag)
+			}
+		}
+	case "#|":
+		// previous msgid of fuzzy entries
+	default:
+		p.entry.TranslatorComments = append(p.entry.TranslatorComments, strings.TrimSpace(line[1:]))
+	}
+}
+
+// unquote parses a C string with escapes of C: \n, \t and other single
+// characters, octal \0 to \377 and hex \xFF.
+func (p *poParser) unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", p.errorf("invalid string %s, quote it with \"", s)
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		c := s[i]
+		if c == '"' {
+			return "", p.errorf("invalid string %s, escape \" inside it", s)
+		}
+
+		if c != '\\' {
+			b.WriteByte(c)
+
+			continue
+		}
+
+		i++
+		if i == len(s)-1 {
+			return "", p.errorf("invalid string %s", s)
+		}
+
+		switch c = s[i]; {
+		case strings.IndexByte(`\'"?`, c) >= 0:
+			b.WriteByte(c)
+		case strings.IndexByte("abfnrtv", c) >= 0:
+			b.WriteByte(poEscapes[c])
+		case c >= '0' && c <= '7':
+			n := span(s[i:min(i+3, len(s)-1)], func(c byte) bool { return c >= '0' && c <= '7' })
+			v, _ := strconv.ParseUint(s[i:i+n], 8, 16)
+			if v > 0xff {
+				return "", p.errorf("invalid escape \\%s in string %s", s[i:i+n], s)
+			}
+
+			b.WriteByte(byte(v))
+			i += n - 1
+		case c == 'x':
+			n := span(s[i+1:len(s)-1], isHexDigit)
+			v, err := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+			if err != nil {
+				return "", p.errorf("invalid escape \\x%s in string %s", s[i+1:i+1+n], s)
+			}
+
+			b.WriteByte(byte(v))
+			i += n
+		default:
+			return "", p.errorf("invalid escape \\%c in string %s", c, s)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// poEscapes are characters of single character escapes.
+var poEscapes = map[byte]byte{'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v'}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+var (
+	_ ListableProvider = (*PoMessageProvider)(nil)
+	_ SourceProvider   = (*PoMessageProvider)(nil)
+)
@@ -0,0 +1,243 @@
+package mf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+const testPo = `# Translation of the shop
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+# Cart counter
+#. Items in the cart
+#: cart.go:42 cart.go:50
+#, icu-format
+msgctxt "cart"
+msgid "items"
+msgstr "{num, plural, one {# item} other {# items}}"
+
+msgid "hello"
+msgstr ""
+"Hello,\n"
+"{name} \"friend\""
+
+#, fuzzy
+msgid "draft"
+msgstr "Draft"
+
+msgid "untranslated"
+msgstr ""
+
+#~ msgid "old"
+#~ msgstr "Old"
+`
+
+func TestNewPoDictionary(t *testing.T) {
+	d, err := NewPoDictionary([]byte(testPo))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cart\x04items", "hello"}, slices.Collect(d.IDs()))
+
+	msg, err := d.Get(PoID("cart", "items"))
+	require.NoError(t, err)
+	assert.Equal(t, "{num, plural, one {# item} other {# items}}", msg)
+
+	msg, err = d.Get("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello,\n{name} \"friend\"", msg)
+
+	e, ok := d.Entry(PoID("cart", "items"))
+	require.True(t, ok)
+	assert.Equal(t, &PoEntry{
+		Context:            "cart",
+		ID:                 "items",
+		Str:                "{num, plural, one {# item} other {# items}}",
+		TranslatorComments: []string{"Cart counter"},
+		ExtractedComments:  []string{"Items in the cart"},
+		References:         []string{"cart.go:42", "cart.go:50"},
+		Flags:              []string{"icu-format"},
+		Line:               10,
+	}, e)
+	assert.Equal(t, 14, d.Line("hello"))
+
+	d, err = NewPoDictionary([]byte(testPo), PoIncludeFuzzy())
+	require.NoError(t, err)
+
+	msg, err = d.Get("draft")
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", msg)
+}
+
+func TestNewPoDictionary_Escapes(t *testing.T) {
+	d, err := NewPoDictionary([]byte(`msgid "a"
+msgstr "\0\101\1012\x41\x4a!\a\b\f\n\r\t\v\\\"\'\?"`))
+	require.NoError(t, err)
+
+	msg, err := d.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "\x00AA2AJ!\a\b\f\n\r\t\v\\\"'?", msg)
+}
+
+func TestNewPoDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"duplicate", "msgid \"a\"\nmsgstr \"x\"\n\nmsgid \"a\"\nmsgstr \"y\"", `line 4: duplicate message "a", first defined on line 1`},
+		{"plural", "msgid \"a\"\nmsgid_plural \"as\"\nmsgstr[0] \"x\"", "line 1: msgid_plural is not supported, use ICU plural in msgstr"},
+		{"no msgstr", "msgid \"a\"\n\nmsgid \"b\"\nmsgstr \"y\"", `line 1: msgid "a" without msgstr`},
+		{"no msgid", "msgstr \"x\"", "line 1: msgstr without msgid"},
+		{"unquoted", "msgid a", "line 1: invalid string a, quote it with \""},
+		{"keyword", "msgfoo \"a\"", "line 1: unexpected keyword msgfoo"},
+		{"string", "\"a\"", "line 1: unexpected string, expected msgid, msgstr or msgctxt before it"},
+		{"escape", `msgid "a\q"`, `line 1: invalid escape \q in string "a\q"`},
+		{"octal escape", `msgid "\400"`, `line 1: invalid escape \400 in string "\400"`},
+		{"hex escape", `msgid "\xg"`, `line 1: invalid escape \x in string "\xg"`},
+		{"quote", `msgid "a"b"`, `line 1: invalid string "a"b", escape " inside it`},
+		{"trailing backslash", `msgid "a\"`, `line 1: invalid string "a\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPoDictionary([]byte(tt.in))
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+// testMo compiles messages to a little endian MO file.
+func testMo(msgs [][2]string) []byte {
+	var strs bytes.Buffer
+
+	header := 28 + 16*len(msgs)
+	table := make([]uint32, 0, 4*len(msgs))
+	for _, col := range []int{0, 1} {
+		for _, m := range msgs {
+			table = append(table, uint32(len(m[col])), uint32(header+strs.Len())) //nolint: gosec
+			strs.WriteString(m[col] + "\x00")
+		}
+	}
+
+	data := binary.LittleEndian.AppendUint32(nil, 0x950412de)
+	for _, v := range []uint32{0, uint32(len(msgs)), 28, uint32(28 + 8*len(msgs)), 0, 0} { //nolint: gosec
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+
+	for _, v := range table {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+
+	return append(data, strs.Bytes()...)
+}
+
+func TestNewMoDictionary(t *testing.T) {
+	d, err := NewMoDictionary(testMo([][2]string{
+		{"", "Content-Type: text/plain; charset=UTF-8\n"},
+		{"cart\x04items", "{num, plural, one {# item} other {# items}}"},
+		{"hello", "Hello, {name}"},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cart\x04items", "hello"}, slices.Collect(d.IDs()))
+
+	e, ok := d.Entry(PoID("cart", "items"))
+	require.True(t, ok)
+	assert.Equal(t, "cart", e.Context)
+	assert.Equal(t, "{num, plural, one {# item} other {# items}}", e.Str)
+
+	_, err = NewMoDictionary(testMo([][2]string{{"a\x00as", "x\x00y"}}))
+	require.ErrorContains(t, err, "msgid_plural")
+
+	_, err = NewMoDictionary([]byte("not a mo file, definitely"))
+	require.Error(t, err)
+
+	data := testMo([][2]string{{"a", "b"}})
+	_, err = NewMoDictionary(data[:len(data)-3])
+	require.Error(t, err, "truncated")
+}
+
+func TestNewPoMessageProvider(t *testing.T) {
+	p, err := NewPoMessageProvider(fstest.MapFS{
+		"messages.en.po": {Data: []byte(testPo)},
+		"messages.es.mo": {Data: testMo([][2]string{{"hello", "Hola, {name}"}})},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.Spanish}, p.Languages())
+
+	file, line, ok := p.Source(language.English, "hello")
+	assert.True(t, ok)
+	assert.Equal(t, "messages.en.po", file)
+	assert.Equal(t, 14, line)
+
+	e, ok := p.Entry(language.English, PoID("cart", "items"))
+	require.True(t, ok)
+	assert.Equal(t, []string{"cart.go:42", "cart.go:50"}, e.References)
+
+	_, err = NewPoMessageProvider(fstest.MapFS{"messages.en.po": {Data: []byte("msgid \"a\"\nmsgstr \"x\"\nmsgid \"a\"\nmsgstr \"y\"")}})
+	require.EqualError(t, err, `messages.en.po:3: duplicate message "a", first defined on line 1`)
+
+	_, err = NewPoMessageProvider(fstest.MapFS{
+		"messages.en.po": {Data: []byte(testPo)},
+		"messages.en.mo": {Data: testMo(nil)},
+	})
+	require.Error(t, err, "one file per language")
+
+	b, err := NewBundle(WithPoProvider(fstest.MapFS{"messages.en.po": {Data: []byte(testPo)}}))
+	require.NoError(t, err)
+	assert.Equal(t, "2 items", b.Translator("en").Trans(PoID("cart", "items"), Arg("num", 2)))
+}
+
+func TestWritePot(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte("cart:\n  # Items in the cart\n  items: '{num, plural, one {# item} other {# items}}'\nhello: |-\n  Hello,\n  \"{name}\"\n")},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePot(&buf, p, language.English))
+
+	assert.Equal(t, `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+
+#. Items in the cart
+#: messages.en.yaml:3
+msgid "cart.items"
+msgstr ""
+
+#. Hello,
+#. "{name}"
+#: messages.en.yaml:4
+msgid "hello"
+msgstr ""
+`, buf.String())
+
+	po, err := NewPoMessageProvider(fstest.MapFS{
+		"messages.es.po": {Data: bytes.Replace(buf.Bytes(), []byte("msgstr \"\"\n\n#. Hello"), []byte("msgstr \"Artículos\"\n\n#. Hello"), 1)},
+	})
+	require.NoError(t, err)
+
+	var pot bytes.Buffer
+	require.NoError(t, WritePot(&pot, po, language.Spanish))
+	assert.Contains(t, pot.String(), "#. Items in the cart\n#: messages.es.po:8\nmsgid \"cart.items\"\n")
+
+	ctx := &PoMessageProvider{dictionaries: map[language.Tag]*PoDictionary{language.English: newPoDictionary()}}
+	require.NoError(t, ctx.dictionaries[language.English].add(&PoEntry{Context: "menu", ID: "open", Str: "Open"}))
+
+	pot.Reset()
+	require.NoError(t, WritePot(&pot, ctx, language.English))
+	assert.Contains(t, pot.String(), "#. Open\nmsgctxt \"menu\"\nmsgid \"open\"\nmsgstr \"\"\n")
+}
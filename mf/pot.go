@@ -0,0 +1,90 @@
+package mf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// WritePot writes messages of the provider as a gettext PO template.
+// Message ids become msgid, or msgctxt and msgid if they have PoContextSeparator.
+// Descriptions of messages are written as extracted comments for translators
+// if the provider is a DescriptionProvider, messages of lang are written instead
+// of missing ones. Files and lines of messages are written as references
+// if the provider is a SourceProvider.
+func WritePot(w io.Writer, p ListableProvider, lang language.Tag) error {
+	bw := bufio.NewWriter(w)
+
+	writePoString(bw, "msgid", "")
+	writePoString(bw, "msgstr", "Content-Type: text/plain; charset=UTF-8\nContent-Transfer-Encoding: 8bit\n")
+
+	sources, _ := p.(SourceProvider)
+	descriptions, _ := p.(DescriptionProvider)
+	for id := range p.IDs(lang) {
+		msg, err := p.Get(lang, id)
+		if err != nil {
+			return err
+		}
+
+		comment := msg
+		if descriptions != nil {
+			if d := descriptions.Description(lang, id); d != "" {
+				comment = d
+			}
+		}
+
+		bw.WriteString("\n")
+		for _, line := range strings.Split(comment, "\n") {
+			bw.WriteString(strings.TrimRight("#. "+line, " ") + "\n")
+		}
+
+		if sources != nil {
+			if file, line, ok := sources.Source(lang, id); ok {
+				fmt.Fprintf(bw, "#: %s:%d\n", file, line)
+			}
+		}
+
+		if ctx, msgid, ok := strings.Cut(id, PoContextSeparator); ok {
+			writePoString(bw, "msgctxt", ctx)
+			id = msgid
+		}
+
+		writePoString(bw, "msgid", id)
+		writePoString(bw, "msgstr", "")
+	}
+
+	return bw.Flush()
+}
+
+// writePoString writes keyword with quoted s, multiline strings are split after newlines.
+func writePoString(w *bufio.Writer, keyword, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) <= 1 {
+		fmt.Fprintf(w, "%s %s\n", keyword, poQuote(s))
+
+		return
+	}
+
+	fmt.Fprintf(w, "%s \"\"\n", keyword)
+	for _, line := range lines {
+		w.WriteString(poQuote(line) + "\n")
+	}
+}
+
+// poQuote quotes s as a C string, non ASCII characters are kept as is.
+func poQuote(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\t", `\t`,
+		"\r", `\r`,
+	).Replace(s) + `"`
+}
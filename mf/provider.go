@@ -4,28 +4,82 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"iter"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/text/language"
 )
 
+// MessageProvider returns raw ICU messages,
+// errors for missing messages should wrap ErrMessageNotFound.
 type MessageProvider interface {
 	Get(lang language.Tag, id string) (string, error)
 }
 
+// ListableProvider is implemented by providers that are able to list their messages,
+// e.g. for coverage reports, admin UIs or export.
+type ListableProvider interface {
+	MessageProvider
+	// Languages returns languages with messages.
+	Languages() []language.Tag
+	// IDs returns ids of all messages for the language.
+	IDs(lang language.Tag) iter.Seq[string]
+}
+
+// SourceProvider is implemented by providers that know where messages are defined.
+type SourceProvider interface {
+	// Source returns file and line of the message, line is 0 if unknown.
+	Source(lang language.Tag, id string) (file string, line int, ok bool)
+}
+
+// DescriptionProvider is implemented by providers with descriptions of messages
+// for translators, like comments of yaml keys or #. comments of PO files.
+type DescriptionProvider interface {
+	// Description returns description of the message, or empty string.
+	Description(lang language.Tag, id string) string
+}
+
+// PlaceholderProvider is implemented by providers with declared arguments of messages,
+// like placeholders of ARB files. Check reports arguments that do not match them.
+type PlaceholderProvider interface {
+	// Placeholders returns declared arguments of the message, or nil if none are declared.
+	Placeholders(lang language.Tag, id string) []Placeholder
+}
+
+// Placeholder is a declared argument of a message.
+type Placeholder struct {
+	Name string
+	// Type is a Dart type of ARB placeholders, like String, int, num or DateTime.
+	Type        string
+	Format      string
+	Example     string
+	Description string
+}
+
 type YamlMessageProvider struct {
-	dictionaries map[language.Tag]*YamlDictionary
+	dictionaries[*YamlDictionary]
 }
 
-func (p *YamlMessageProvider) Get(lang language.Tag, path string) (string, error) {
+func (p *YamlMessageProvider) Syntax(lang language.Tag, id string) Syntax {
 	d, hasDictionary := p.dictionaries[lang]
 	if !hasDictionary {
-		return "", errors.Errorf("no dictionary for lang %s", lang)
+		return SyntaxICU
 	}
 
-	return d.Get(path)
+	return d.Syntax(id)
+}
+
+// Description returns comments of the message key.
+func (p *YamlMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
 }
 
 func NewYamlMessageProvider(dir fs.FS) (*YamlMessageProvider, error) {
@@ -33,16 +87,107 @@ func NewYamlMessageProvider(dir fs.FS) (*YamlMessageProvider, error) {
 		dictionaries: map[language.Tag]*YamlDictionary{},
 	}
 
-	err := fs.WalkDir(dir, ".", func(p string, f fs.DirEntry, err error) error {
+	err := walkLangFiles(dir, []string{".yaml", ".yml"}, func(path string, lang language.Tag) error {
+		return provider.loadMessages(dir, path, lang)
+	})
+
+	return &provider, err
+}
+
+func (p *YamlMessageProvider) loadMessages(rd fs.FS, path string, lang language.Tag) error {
+	yamlData, err := readFile(rd, path)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(path, lang); err != nil {
+		return err
+	}
+
+	d, err := NewYamlDictionary(yamlData)
+	if err != nil {
+		return errors.Wrap(err, "unable to create dictionary")
+	}
+
+	d.file = path
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// dictionaries are messages loaded from files, by language.
+type dictionaries[D fileDictionary] map[language.Tag]D
+
+// fileDictionary is a dictionary loaded from a file.
+type fileDictionary interface {
+	Get(id string) (string, error)
+	IDs() iter.Seq[string]
+	Line(id string) int
+	filename() string
+}
+
+func (ds dictionaries[D]) Get(lang language.Tag, path string) (string, error) {
+	d, hasDictionary := ds[lang]
+	if !hasDictionary {
+		return "", errors.Wrapf(ErrMessageNotFound, "no dictionary for lang %s", lang)
+	}
+
+	return d.Get(path)
+}
+
+// Languages returns languages with loaded messages, sorted by tag.
+func (ds dictionaries[D]) Languages() []language.Tag {
+	langs := make([]language.Tag, 0, len(ds))
+	for lang := range ds {
+		langs = append(langs, lang)
+	}
+
+	slices.SortFunc(langs, func(a, b language.Tag) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return langs
+}
+
+// IDs returns sorted ids of all messages for the language.
+func (ds dictionaries[D]) IDs(lang language.Tag) iter.Seq[string] {
+	d, hasDictionary := ds[lang]
+	if !hasDictionary {
+		return func(func(string) bool) {}
+	}
+
+	return d.IDs()
+}
+
+func (ds dictionaries[D]) Source(lang language.Tag, id string) (string, int, bool) {
+	d, hasDictionary := ds[lang]
+	if !hasDictionary {
+		return "", 0, false
+	}
+
+	line := d.Line(id)
+
+	return d.filename(), line, line > 0
+}
+
+// checkLang reports a second file of the same language.
+func (ds dictionaries[D]) checkLang(path string, lang language.Tag) error {
+	if _, hasDictionary := ds[lang]; hasDictionary {
+		return fmt.Errorf("unable to load %s: language %s already has messages loaded", path, lang)
+	}
+
+	return nil
+}
+
+// walkLangFiles calls load for files with one of the extensions,
+// language is the last but one part of the name, like en in messages.en.yaml.
+func walkLangFiles(dir fs.FS, exts []string, load func(path string, lang language.Tag) error) error {
+	return fs.WalkDir(dir, ".", func(p string, f fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if f.IsDir() {
-			return nil
-		}
-
-		if path.Ext(f.Name()) != ".yaml" && path.Ext(f.Name()) != ".yml" {
+		if f.IsDir() || !slices.Contains(exts, path.Ext(f.Name())) {
 			return nil
 		}
 
@@ -56,32 +201,52 @@ func NewYamlMessageProvider(dir fs.FS) (*YamlMessageProvider, error) {
 			return errors.Wrap(err, "unable to parse language from filename")
 		}
 
-		return provider.loadMessages(dir, p, tag)
+		return load(p, tag)
 	})
+}
 
-	return &provider, err
+// lineError is an error at a line of a file.
+type lineError struct {
+	Line int
+	Msg  string
 }
 
-func (p *YamlMessageProvider) loadMessages(rd fs.FS, path string, lang language.Tag) error {
-	yamlFile, err := rd.Open(path)
-	if err != nil {
-		return errors.Wrap(err, "unable to open file")
-	}
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
 
-	yamlData, err := io.ReadAll(yamlFile)
-	if err != nil {
-		return errors.Wrap(err, "unable to read file")
+// fileError reports errors of a dictionary loaded from the file,
+// errors at a line are reported as file:line: message.
+func fileError(path string, err error) error {
+	var lineErr *lineError
+	if errors.As(err, &lineErr) {
+		return fmt.Errorf("%s:%d: %s", path, lineErr.Line, lineErr.Msg)
 	}
 
-	_, hasDictionary := p.dictionaries[lang]
-	if hasDictionary {
-		return fmt.Errorf("unable to load %s: language %s already has messages loaded", path, lang)
+	return errors.Wrapf(err, "unable to create dictionary from %s", path)
+}
+
+func readFile(rd fs.FS, path string) ([]byte, error) {
+	f, err := rd.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open file")
 	}
+	defer f.Close()
 
-	p.dictionaries[lang], err = NewYamlDictionary(yamlData)
+	data, err := io.ReadAll(f)
 	if err != nil {
-		return errors.Wrap(err, "unable to create dictionary")
+		return nil, errors.Wrap(err, "unable to read file")
 	}
 
-	return nil
+	return data, nil
 }
+
+var (
+	_ ListableProvider = (*YamlMessageProvider)(nil)
+	_ SourceProvider   = (*YamlMessageProvider)(nil)
+	_ SyntaxProvider   = (*YamlMessageProvider)(nil)
+
+	_ DescriptionProvider = (*YamlMessageProvider)(nil)
+	_ DescriptionProvider = (*JsonMessageProvider)(nil)
+	_ DescriptionProvider = (*PoMessageProvider)(nil)
+)
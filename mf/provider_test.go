@@ -2,6 +2,7 @@ package mf
 
 import (
 	"io/fs"
+	"slices"
 	"testing"
 	"testing/fstest"
 
@@ -62,3 +63,17 @@ func TestFSProvider_loadMessages(t *testing.T) {
 	require.Error(t, p.loadMessages(fs, "foo.en.yaml", language.English), "error when lang already loaded")
 	assert.NotNil(t, p.dictionaries[language.English])
 }
+
+func TestYamlMessageProvider_List(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte("foo: bar\nbar:\n  baz: qux")},
+		"messages.es.yaml": {Data: []byte("foo: bar")},
+		"messages.ru.yml":  {Data: []byte("")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.Spanish, language.Russian}, p.Languages())
+	assert.Equal(t, []string{"bar.baz", "foo"}, slices.Collect(p.IDs(language.English)))
+	assert.Empty(t, slices.Collect(p.IDs(language.Russian)))
+	assert.Empty(t, slices.Collect(p.IDs(language.German)))
+}
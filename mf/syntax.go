@@ -0,0 +1,37 @@
+package mf
+
+import "golang.org/x/text/language"
+
+// Syntax of a raw message.
+type Syntax int
+
+const (
+	// SyntaxICU is ICU MessageFormat, the default.
+	SyntaxICU Syntax = iota
+	// SyntaxMF2 is Unicode MessageFormat 2.
+	SyntaxMF2
+)
+
+func (s Syntax) String() string {
+	switch s {
+	case SyntaxMF2:
+		return "mf2"
+	default:
+		return "icu"
+	}
+}
+
+// SyntaxProvider is implemented by providers with messages in several syntaxes,
+// messages of other providers are ICU.
+type SyntaxProvider interface {
+	Syntax(lang language.Tag, id string) Syntax
+}
+
+// messageSyntax returns syntax of the message if provider knows it.
+func messageSyntax(p MessageProvider, lang language.Tag, id string) Syntax {
+	if sp, ok := p.(SyntaxProvider); ok {
+		return sp.Syntax(lang, id)
+	}
+
+	return SyntaxICU
+}
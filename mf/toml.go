@@ -0,0 +1,219 @@
+package mf
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// TomlMessageProvider loads messages from messages.<lang>.toml files.
+type TomlMessageProvider struct {
+	dictionaries[*TomlDictionary]
+}
+
+func NewTomlMessageProvider(dir fs.FS) (*TomlMessageProvider, error) {
+	provider := TomlMessageProvider{
+		dictionaries: map[language.Tag]*TomlDictionary{},
+	}
+
+	err := walkLangFiles(dir, []string{".toml"}, func(path string, lang language.Tag) error {
+		return provider.loadMessages(dir, path, lang)
+	})
+
+	return &provider, err
+}
+
+func (p *TomlMessageProvider) loadMessages(rd fs.FS, path string, lang language.Tag) error {
+	data, err := readFile(rd, path)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(path, lang); err != nil {
+		return err
+	}
+
+	d, err := NewTomlDictionary(data)
+	if err != nil {
+		return fileError(path, err)
+	}
+
+	d.file = path
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// TomlDictionary is a dictionary of TOML tables:
+//
+//	title = "Hello"
+//
+//	[cart]
+//	items = "{num, plural, one {# item} other {# items}}"
+//
+// Keys of nested and inline tables are joined with dots, cart.items here.
+// Strings, numbers, booleans and dates are messages, arrays are skipped,
+// arrays of tables are not supported. Numbers are formatted
+// as decimals, so 1_000 and 1e3 are both 1000.
+type TomlDictionary struct {
+	flatDictionary
+}
+
+func NewTomlDictionary(data []byte) (*TomlDictionary, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	lines, starts, err := tomlKeyLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, tomlError(data, starts, err)
+	}
+
+	d := &TomlDictionary{flatDictionary: newFlatDictionary()}
+	d.flatten("", doc, lines)
+
+	return d, nil
+}
+
+func (d *TomlDictionary) flatten(prefix string, table map[string]any, lines map[string]int) {
+	for key, v := range table {
+		id := prefix + key
+
+		var msg string
+		switch v := v.(type) {
+		case map[string]any:
+			d.flatten(id+".", v, lines)
+
+			continue
+		case []any:
+			continue
+		case string:
+			msg = v
+		case int64:
+			msg = strconv.FormatInt(v, 10)
+		case float64:
+			msg = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			msg = strconv.FormatBool(v)
+		case time.Time:
+			msg = v.Format(time.RFC3339Nano)
+		case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+			msg = fmt.Sprint(v)
+		default:
+			continue
+		}
+
+		d.flatMap[id] = msg
+		d.lines[id] = lines[id]
+	}
+}
+
+// tomlKeyLines returns lines of keys of values, joined with dots,
+// and offsets of lines where expressions start.
+// It fails on syntax errors and arrays of tables.
+func tomlKeyLines(data []byte) (map[string]int, []int, error) {
+	var (
+		p      unstable.Parser
+		table  string
+		starts []int
+		lines  = map[string]int{}
+	)
+
+	line := func(n *unstable.Node) int {
+		return p.Shape(n.Raw).Start.Line
+	}
+
+	var keyValue func(prefix string, n *unstable.Node)
+	keyValue = func(prefix string, n *unstable.Node) {
+		key := n.Key()
+		key.Next()
+		first := key.Node()
+		id := prefix + tomlKey(first, &key)
+		lines[id] = line(first)
+
+		if v := n.Value(); v.Kind == unstable.InlineTable {
+			for c := v.Children(); c.Next(); {
+				keyValue(id+".", c.Node())
+			}
+		}
+	}
+
+	p.Reset(data)
+	for p.NextExpression() {
+		n := p.Expression()
+
+		switch n.Kind {
+		case unstable.Table:
+			key := n.Key()
+			key.Next()
+			first := key.Node()
+			starts = append(starts, lineStart(data, int(first.Raw.Offset)))
+			table = tomlKey(first, &key) + "."
+		case unstable.ArrayTable:
+			key := n.Key()
+			key.Next()
+
+			return nil, nil, &lineError{Line: line(key.Node()), Msg: "arrays of tables are not supported"}
+		case unstable.KeyValue:
+			starts = append(starts, lineStart(data, int(n.Raw.Offset)))
+			keyValue(table, n)
+		}
+	}
+
+	// syntax errors are reported by toml.Unmarshal with positions
+	return lines, starts, nil
+}
+
+// tomlKey joins first and the rest of dotted key with dots.
+func tomlKey(first *unstable.Node, rest *unstable.Iterator) string {
+	key := string(first.Data)
+	for rest.Next() {
+		key += "." + string(rest.Node().Data)
+	}
+
+	return key
+}
+
+func lineStart(data []byte, offset int) int {
+	return bytes.LastIndexByte(data[:offset], '\n') + 1
+}
+
+// tomlError adds line to the error of toml.Unmarshal. Errors of keys defined twice
+// have no position, their line is where a prefix of expressions becomes invalid.
+func tomlError(data []byte, starts []int, err error) error {
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		line, _ := decodeErr.Position()
+
+		return &lineError{Line: line, Msg: strings.TrimPrefix(decodeErr.Error(), "toml: ")}
+	}
+
+	msg := strings.TrimPrefix(err.Error(), "toml: ")
+	i := sort.Search(len(starts), func(i int) bool {
+		end := len(data)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+
+		var doc map[string]any
+
+		return toml.Unmarshal(data[:end], &doc) != nil
+	})
+	if i == len(starts) {
+		return errors.New(msg)
+	}
+
+	return &lineError{Line: bytes.Count(data[:starts[i]], []byte("\n")) + 1, Msg: msg}
+}
@@ -0,0 +1,134 @@
+package mf
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestNewTomlDictionary(t *testing.T) {
+	d, err := NewTomlDictionary([]byte(`# greetings
+title = "Hello, {name}!" # comment
+"quoted.key" = 'C:\path'
+count = 1_000
+rate = 1e3
+enabled = true
+list = ["a", { b = "c" }, [1, 2],
+]
+site.name = "Site"
+
+[cart]
+items = "{num, plural, one {# item} other {# items}}"
+empty = """
+Cart is \
+    empty"""
+note = '''
+It's "raw" \n'''
+esc = "tab\there \u00e9 \"q\""
+inline = { a = "x", b.c = "y" }
+
+[cart.checkout]
+pay = "Pay"
+
+[shop]
+url = "example.com"
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"cart.checkout.pay",
+		"cart.empty",
+		"cart.esc",
+		"cart.inline.a",
+		"cart.inline.b.c",
+		"cart.items",
+		"cart.note",
+		"count",
+		"enabled",
+		"quoted.key",
+		"rate",
+		"shop.url",
+		"site.name",
+		"title",
+	}, slices.Collect(d.IDs()))
+
+	for id, want := range map[string]string{
+		"title":           "Hello, {name}!",
+		"quoted.key":      `C:\path`,
+		"count":           "1000",
+		"rate":            "1000",
+		"cart.empty":      "Cart is empty",
+		"cart.note":       `It's "raw" \n`,
+		"cart.esc":        "tab\there é \"q\"",
+		"cart.inline.b.c": "y",
+	} {
+		got, err := d.Get(id)
+		require.NoError(t, err, id)
+		assert.Equal(t, want, got, id)
+	}
+
+	assert.Equal(t, 2, d.Line("title"))
+	assert.Equal(t, 12, d.Line("cart.items"))
+	assert.Equal(t, 13, d.Line("cart.empty"))
+	assert.Equal(t, 19, d.Line("cart.inline.b.c"))
+	assert.Equal(t, 22, d.Line("cart.checkout.pay"))
+	assert.Equal(t, 25, d.Line("shop.url"))
+}
+
+func TestNewTomlDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"duplicate key", "a = \"x\"\n\na = \"y\"", "line 3: key a is already defined"},
+		{"duplicate in table", "[t]\na = \"x\"\n[t]\nb = \"y\"", "line 3: table t already exists"},
+		{"key is a message", "a = \"x\"\n[a.b]", "line 2: expected a to be a table, not a value"},
+		{"dotted key is a message", "a = \"x\"\na.b = \"y\"", "line 2: expected a to be a table, not a value"},
+		{"dotted key is a table", "a.b = \"x\"\n\n[a]\nc = \"y\"", "line 3: table a already exists"},
+		{"key is a table", "[a.b]\nc = \"x\"\n[a]\nb = \"y\"", "line 4: key b is already defined"},
+		{"inline table", "a = { b = \"x\", b = \"y\" }", "line 1: key b is already defined"},
+		{"unquoted string", "a = hello", "line 1: incomplete number"},
+		{"unterminated string", "a = \"x\nb = \"y\"", "line 1: basic strings cannot have new lines"},
+		{"trailing text", "\na = \"x\" b", "line 2: expected newline but got U+0062 'b'"},
+		{"array of tables", "a = 'x'\n[[a]]", "line 2: arrays of tables are not supported"},
+		{"invalid escape", "a = \"\\q\"", "line 1: invalid escaped character U+0071 'q'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTomlDictionary([]byte(tt.in))
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestNewTomlMessageProvider(t *testing.T) {
+	p, err := NewTomlMessageProvider(fstest.MapFS{
+		"messages.en.toml": {Data: []byte("[cart]\nitems = \"{num, plural, one {# item} other {# items}}\"")},
+		"messages.es.toml": {Data: []byte("foo = 'bar'")},
+		"messages.de.json": {Data: []byte(`{"foo": "bar"}`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.English, language.Spanish}, p.Languages())
+
+	file, line, ok := p.Source(language.English, "cart.items")
+	assert.True(t, ok)
+	assert.Equal(t, "messages.en.toml", file)
+	assert.Equal(t, 2, line)
+
+	_, err = NewTomlMessageProvider(fstest.MapFS{"messages.en.toml": {Data: []byte("a = 'x'\na = 'y'")}})
+	require.EqualError(t, err, "messages.en.toml:2: key a is already defined")
+
+	b, err := NewBundle(WithTomlProvider(fstest.MapFS{
+		"messages.en.toml": {Data: []byte("[cart]\nitems = \"{num, plural, one {# item} other {# items}}\"")},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "1 item", b.Translator("en").Trans("cart.items", Arg("num", 1)))
+}
@@ -1,67 +1,166 @@
 package mf
 
 import (
-	"strings"
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/fullpipe/icu-mf/message"
 	"github.com/fullpipe/icu-mf/parse"
+	"github.com/fullpipe/icu-mf/parse/mf2"
 	"golang.org/x/exp/constraints"
 	"golang.org/x/text/language"
 )
 
 type Translator interface {
+	// Trans translates message, on failure error handler is called and id is returned.
 	Trans(id string, args ...TranslationArg) string
+	// TransCtx is like Trans, but stops on canceled context and
+	// uses request-scoped options, like WithTimezone and WithCurrency.
+	TransCtx(ctx context.Context, id string, args ...TranslationArg) string
+	// TransE translates message or returns *TranslationError.
+	TransE(id string, args ...TranslationArg) (string, error)
+	// TransDefault translates message, if there is no message with id
+	// in any fallback language, defaultMessage is used instead.
+	TransDefault(id string, defaultMessage string, args ...TranslationArg) string
+	// Translate is like TransE, but also tells the language the message was found in.
+	Translate(id string, args ...TranslationArg) (Translation, error)
+	// Has checks if message exists in translator language or its fallbacks.
+	Has(id string) bool
+	// Lang returns translator language.
+	Lang() language.Tag
+}
+
+// Translation is a translated message with the language it was found in.
+type Translation struct {
+	Text string
+	Lang language.Tag
+	// Fallback is true if the message was not found in the translator language,
+	// even if it was found in its parent, like es for es-MX.
+	Fallback bool
 }
 
 type translator struct {
 	provider     MessageProvider
-	fallback     Translator
+	fallback     *translator
 	errorHandler ErrorHandler
 	lang         language.Tag
+	buildOptions []message.BuildOption
 }
 
 func (tr *translator) Trans(id string, args ...TranslationArg) string {
-	yaml, err := tr.provider.Get(tr.lang, id)
-	if err != nil {
-		if tr.fallback != nil {
-			return tr.fallback.Trans(id, args...)
-		}
+	return tr.TransCtx(context.Background(), id, args...)
+}
 
-		tr.errorHandler(err, id, nil)
+func (tr *translator) TransCtx(ctx context.Context, id string, args ...TranslationArg) string {
+	translation, mctx, err := tr.trans(ctx, id, nil, args)
+	if err != nil {
+		tr.errorHandler(err, id, mctx)
 
 		return id
 	}
 
-	parser := parse.NewParser()
+	return translation.Text
+}
+
+func (tr *translator) TransE(id string, args ...TranslationArg) (string, error) {
+	translation, _, err := tr.trans(context.Background(), id, nil, args)
+
+	return translation.Text, err
+}
 
-	msg, err := parser.Parse("", strings.NewReader(yaml))
+func (tr *translator) TransDefault(id string, defaultMessage string, args ...TranslationArg) string {
+	translation, mctx, err := tr.trans(context.Background(), id, &defaultMessage, args)
 	if err != nil {
-		tr.errorHandler(err, id, nil)
+		tr.errorHandler(err, id, mctx)
 
 		return id
 	}
 
-	eval, err := message.Build(*msg, tr.lang)
-	if err != nil {
-		tr.errorHandler(err, id, nil)
+	return translation.Text
+}
 
-		return id
-	}
+func (tr *translator) Translate(id string, args ...TranslationArg) (Translation, error) {
+	translation, _, err := tr.trans(context.Background(), id, nil, args)
 
-	ctx := make(message.Context, len(args))
+	return translation, err
+}
+
+func (tr *translator) Lang() language.Tag {
+	return tr.lang
+}
+
+func (tr *translator) Has(id string) bool {
+	_, _, err := tr.lookup(id)
+
+	return err == nil
+}
+
+// trans finds, compiles and evaluates message with the context,
+// defaultMessage is used if it is not nil and message does not exist.
+func (tr *translator) trans(ctx context.Context, id string, defaultMessage *string, args []TranslationArg) (Translation, message.Context, error) {
+	mctx := make(message.Context, len(args))
 	for _, arg := range args {
-		arg(&ctx)
+		arg(&mctx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Translation{}, mctx, newTranslationError(tr.lang, tr.lang, id, err)
+	}
+
+	syntax := SyntaxICU
+
+	src, lang, err := tr.lookup(id)
+	if err == nil {
+		syntax = messageSyntax(tr.provider, lang, id)
+	} else {
+		if defaultMessage == nil {
+			return Translation{}, mctx, newTranslationError(tr.lang, tr.lang, id, err)
+		}
+
+		src, lang = *defaultMessage, tr.lang
 	}
 
-	translation, err := eval.Eval(ctx)
+	eval, err := tr.compile(src, syntax, lang)
 	if err != nil {
-		tr.errorHandler(err, id, ctx)
+		return Translation{}, mctx, newTranslationError(tr.lang, lang, id, err)
+	}
 
-		return id
+	text, err := message.EvalEnv(eval, mctx, messageEnv(ctx))
+	if err != nil {
+		return Translation{}, mctx, newTranslationError(tr.lang, lang, id, err)
+	}
+
+	return Translation{Text: text, Lang: lang, Fallback: lang != tr.lang}, mctx, nil
+}
+
+// lookup returns raw message from translator language or its fallbacks,
+// with the language it was found in.
+func (tr *translator) lookup(id string) (string, language.Tag, error) {
+	src, err := tr.provider.Get(tr.lang, id)
+	if err != nil && tr.fallback != nil {
+		return tr.fallback.lookup(id)
 	}
 
-	return translation
+	return src, tr.lang, err
+}
+
+func (tr *translator) compile(src string, syntax Syntax, lang language.Tag) (message.Evalable, error) {
+	if syntax == SyntaxMF2 {
+		msg, err := mf2.Parse(src)
+		if err != nil {
+			return nil, err
+		}
+
+		return message.BuildMF2(msg, lang, tr.buildOptions...)
+	}
+
+	msg, err := parse.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.Build(*msg, lang, tr.buildOptions...)
 }
 
 type TranslationArg func(ctx *message.Context)
@@ -81,3 +180,19 @@ func Arg[T Argument](name string, value T) TranslationArg {
 		ctx.Set(name, value)
 	}
 }
+
+// Bool adds an argument that is selected by "true" or "false" key,
+// e.g. {premium, select, true {...} other {...}}.
+func Bool(name string, value bool) TranslationArg {
+	return func(ctx *message.Context) {
+		ctx.Set(name, value)
+	}
+}
+
+// Stringer adds an argument that is selected by its String() value,
+// e.g. enums in {status, select, active {...} other {...}}.
+func Stringer(name string, value fmt.Stringer) TranslationArg {
+	return func(ctx *message.Context) {
+		ctx.Set(name, value)
+	}
+}
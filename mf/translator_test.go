@@ -2,10 +2,12 @@ package mf
 
 import (
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/text/language"
 )
 
@@ -115,6 +117,30 @@ func Test_translator_Trans(t *testing.T) {
 			false,
 		},
 
+		{
+			"select on bool",
+			"{is_admin, select, true {admin} false {user} other {unknown}}",
+			language.English,
+			[]TranslationArg{Bool("is_admin", true)},
+			"admin",
+			false,
+		},
+		{
+			"select on stringer",
+			"{plan, select, free {free plan} pro {pro plan} other {custom plan}}",
+			language.English,
+			[]TranslationArg{Stringer("plan", testPlanPro)},
+			"pro plan",
+			false,
+		},
+		{
+			"select on stringer #2",
+			"{plan, select, free {free plan} pro {pro plan} other {custom plan}}",
+			language.English,
+			[]TranslationArg{Stringer("plan", testPlanFree)},
+			"free plan",
+			false,
+		},
 		// plural
 		{
 			"error on empty plural",
@@ -428,7 +454,7 @@ func Test_translator_Trans(t *testing.T) {
 		},
 		{
 			"escaping single curly brace",
-			"foo '{ {foo}.",
+			"foo '{' {foo}.",
 			language.English,
 			[]TranslationArg{Arg("foo", "bar")},
 			"foo { bar.",
@@ -452,7 +478,7 @@ func Test_translator_Trans(t *testing.T) {
 		},
 		{
 			"escaping",
-			"foo '{ ''{foo} {num, plural, one {''#'' '# ' '{ one}, other {other}}.",
+			"foo '{' ''{foo} {num, plural, one {''#'' '#' ' '{' one}, other {other}}.",
 			language.English,
 			[]TranslationArg{Arg("foo", "bar"), Arg("num", 1)},
 			"foo { 'bar '1' # ' { one.",
@@ -460,7 +486,7 @@ func Test_translator_Trans(t *testing.T) {
 		},
 		{
 			"escaping",
-			"'{foo} is ''{foo}''",
+			"'{foo}' is ''{foo}''",
 			language.English,
 			[]TranslationArg{Arg("foo", "bar")},
 			"{foo} is 'bar'",
@@ -497,6 +523,120 @@ func Test_translator_Trans(t *testing.T) {
 	}
 }
 
+func Test_translator_TransE(t *testing.T) {
+	provider := new(MockedProvider)
+	provider.On("Get", language.Spanish, "hello").Return("Hola {name}!", nil)
+	provider.On("Get", language.Spanish, mock.Anything).Return("", ErrMessageNotFound)
+	provider.On("Get", language.English, "bye").Return("Bye {name}!", nil)
+	provider.On("Get", language.English, mock.Anything).Return("", ErrMessageNotFound)
+
+	tr := &translator{
+		provider: provider,
+		lang:     language.Spanish,
+		fallback: &translator{provider: provider, lang: language.English},
+	}
+
+	got, err := tr.TransE("hello", Arg("name", "Bob"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hola Bob!", got)
+
+	got, err = tr.TransE("bye", Arg("name", "Bob"))
+	require.NoError(t, err, "message from fallback")
+	assert.Equal(t, "Bye Bob!", got)
+
+	got, err = tr.TransE("hello")
+	require.ErrorIs(t, err, ErrArgumentMissing)
+	assert.Empty(t, got)
+
+	_, err = tr.TransE("nope")
+	require.ErrorIs(t, err, ErrMessageNotFound)
+
+	var trErr *TranslationError
+	require.ErrorAs(t, err, &trErr)
+	assert.Equal(t, "nope", trErr.ID)
+	assert.Equal(t, language.Spanish, trErr.Lang, "translator language")
+	assert.Equal(t, language.Spanish, trErr.MessageLang)
+}
+
+func Test_translator_Translate(t *testing.T) {
+	provider := new(MockedProvider)
+	provider.On("Get", language.Spanish, "hello").Return("Hola!", nil)
+	provider.On("Get", language.Spanish, mock.Anything).Return("", ErrMessageNotFound)
+	provider.On("Get", language.English, "bye").Return("Bye!", nil)
+	provider.On("Get", language.English, mock.Anything).Return("", ErrMessageNotFound)
+
+	tr := &translator{
+		provider: provider,
+		lang:     language.Spanish,
+		fallback: &translator{provider: provider, lang: language.English},
+	}
+
+	assert.Equal(t, language.Spanish, tr.Lang())
+
+	got, err := tr.Translate("hello")
+	require.NoError(t, err)
+	assert.Equal(t, Translation{Text: "Hola!", Lang: language.Spanish, Fallback: false}, got)
+
+	got, err = tr.Translate("bye")
+	require.NoError(t, err)
+	assert.Equal(t, Translation{Text: "Bye!", Lang: language.English, Fallback: true}, got)
+
+	_, err = tr.Translate("nope")
+	require.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func Test_translator_Has(t *testing.T) {
+	provider := new(MockedProvider)
+	provider.On("Get", language.Spanish, "hello").Return("Hola!", nil)
+	provider.On("Get", language.Spanish, mock.Anything).Return("", ErrMessageNotFound)
+	provider.On("Get", language.English, "bye").Return("{broken", nil)
+	provider.On("Get", language.English, mock.Anything).Return("", ErrMessageNotFound)
+
+	tr := &translator{
+		provider: provider,
+		lang:     language.Spanish,
+		fallback: &translator{provider: provider, lang: language.English},
+	}
+
+	assert.True(t, tr.Has("hello"))
+	assert.True(t, tr.Has("bye"), "exists in fallback, even if invalid")
+	assert.False(t, tr.Has("nope"))
+}
+
+func Test_translator_TransDefault(t *testing.T) {
+	provider := new(MockedProvider)
+	provider.On("Get", language.Russian, "apples").Return("{num, plural, one {# яблоко} few {# яблока} other {# яблок}}", nil)
+	provider.On("Get", language.Russian, mock.Anything).Return("", ErrMessageNotFound)
+
+	var handledErr error
+	tr := &translator{
+		provider: provider,
+		lang:     language.Russian,
+		errorHandler: func(err error, _ string, _ map[string]any) {
+			handledErr = err
+		},
+	}
+
+	assert.Equal(t, "3 яблока", tr.TransDefault("apples", "{num} apples", Arg("num", 3)), "default is not used")
+	assert.Equal(t, "3 груши", tr.TransDefault("pears", "{num, plural, one {# груша} few {# груши} other {# груш}}", Arg("num", 3)),
+		"default uses translator language")
+	require.NoError(t, handledErr)
+
+	assert.Equal(t, "pears", tr.TransDefault("pears", "{num"), "invalid default")
+	require.Error(t, handledErr)
+}
+
+type testPlan int
+
+const (
+	testPlanFree testPlan = iota
+	testPlanPro
+)
+
+func (p testPlan) String() string {
+	return [...]string{"free", "pro"}[p]
+}
+
 type MockedProvider struct {
 	mock.Mock
 }
@@ -516,3 +656,25 @@ func (m *MockedDictionary) Get(path string) (string, error) {
 
 	return args.String(0), args.Error(1)
 }
+
+func Test_translator_TransMF2(t *testing.T) {
+	b, err := NewBundle(WithYamlProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+icu: "{count, plural, one {# item} other {# items}}"
+invitation: !mf2 |-
+  .input {$gender :string}
+  .input {$count :integer}
+  .match $gender $count
+  female one {{She invited one guest}}
+  female * {{She invited {$count} guests}}
+  * one {{They invited one guest}}
+  * * {{They invited {$count} guests}}
+`)},
+	}), WithStrictValidation())
+	require.NoError(t, err)
+
+	tr := b.Translator("en")
+	assert.Equal(t, "1 item", tr.Trans("icu", Arg("count", 1)))
+	assert.Equal(t, "She invited one guest", tr.Trans("invitation", Arg("gender", "female"), Arg("count", 1)))
+	assert.Equal(t, "They invited 1,000 guests", tr.Trans("invitation", Arg("gender", "male"), Arg("count", 1000)))
+}
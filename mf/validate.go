@@ -0,0 +1,58 @@
+package mf
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// WithStrictValidation parses and builds every message for every language
+// in NewBundle, so broken messages fail on startup, not for users.
+// All failures are returned as *ValidationError.
+// Provider must implement ListableProvider.
+func WithStrictValidation() BundleOption {
+	return func(b *bundle) error {
+		b.strictValidation = true
+
+		return nil
+	}
+}
+
+// validate parses and builds all messages of the provider.
+func (b *bundle) validate() error {
+	lister, ok := b.provider.(ListableProvider)
+	if !ok {
+		return errors.New("strict validation requires provider to implement ListableProvider")
+	}
+
+	tr := &translator{buildOptions: b.buildOptions}
+
+	var errs []*MessageError
+	for _, lang := range lister.Languages() {
+		for id := range lister.IDs(lang) {
+			src, err := b.provider.Get(lang, id)
+			if err == nil {
+				_, err = tr.compile(src, messageSyntax(b.provider, lang, id), lang)
+			}
+
+			if err != nil {
+				errs = append(errs, b.messageError(lang, id, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+func (b *bundle) messageError(lang language.Tag, id string, err error) *MessageError {
+	me := &MessageError{Lang: lang, ID: id, Err: err}
+
+	if sp, ok := b.provider.(SourceProvider); ok {
+		me.File, me.Line, _ = sp.Source(lang, id)
+	}
+
+	return me
+}
@@ -0,0 +1,77 @@
+package mf
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestWithStrictValidation(t *testing.T) {
+	_, err := NewBundle(
+		WithStrictValidation(),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte(`
+hello: Hello {name}!
+user:
+  age: "{age, number, integer}"
+  broken: "{name"
+`)},
+			"messages.es.yaml": {Data: []byte(`
+hello: Hola {name}!
+spell: "{num, spellout}"
+`)},
+		}),
+	)
+	require.Error(t, err)
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 2)
+
+	assert.Equal(t, "messages.en.yaml", verr.Errors[0].File)
+	assert.Equal(t, 5, verr.Errors[0].Line)
+	assert.Equal(t, language.English, verr.Errors[0].Lang)
+	assert.Equal(t, "user.broken", verr.Errors[0].ID)
+
+	var syntaxErr *SyntaxError
+	require.ErrorAs(t, verr.Errors[0], &syntaxErr)
+	assert.Equal(t, 1, syntaxErr.Pos.Line)
+
+	assert.Equal(t, "messages.es.yaml", verr.Errors[1].File)
+	assert.Equal(t, 3, verr.Errors[1].Line)
+	assert.Equal(t, "spell", verr.Errors[1].ID)
+	assert.ErrorIs(t, err, ErrUnsupportedFunction)
+
+	assert.Contains(t, err.Error(), "2 invalid messages:")
+	assert.Contains(t, err.Error(), "messages.en.yaml:5: en user.broken: 1:")
+	assert.Contains(t, err.Error(), "messages.es.yaml:3: es spell: unsupported function")
+}
+
+func TestWithStrictValidation_Valid(t *testing.T) {
+	b, err := NewBundle(
+		WithStrictValidation(),
+		WithYamlProvider(fstest.MapFS{
+			"messages.en.yaml": {Data: []byte("hello: Hello {name}!")},
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Bob!", b.Translator("en").Trans("hello", Arg("name", "Bob")))
+}
+
+type onlyProvider struct{}
+
+func (onlyProvider) Get(language.Tag, string) (string, error) {
+	return "", ErrMessageNotFound
+}
+
+func TestWithStrictValidation_NotListable(t *testing.T) {
+	_, err := NewBundle(WithStrictValidation(), WithProvider(onlyProvider{}))
+	require.Error(t, err)
+
+	var verr *ValidationError
+	assert.False(t, errors.As(err, &verr))
+}
@@ -0,0 +1,536 @@
+package mf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// XliffMessageProvider loads messages from targets of messages.<lang>.xlf
+// or messages.<lang>.xliff files, see XliffDictionary.
+type XliffMessageProvider struct {
+	dictionaries[*XliffDictionary]
+}
+
+func NewXliffMessageProvider(dir fs.FS) (*XliffMessageProvider, error) {
+	provider := XliffMessageProvider{
+		dictionaries: map[language.Tag]*XliffDictionary{},
+	}
+
+	err := walkLangFiles(dir, []string{".xlf", ".xliff"}, func(path string, lang language.Tag) error {
+		return provider.loadMessages(dir, path, lang)
+	})
+
+	return &provider, err
+}
+
+func (p *XliffMessageProvider) loadMessages(rd fs.FS, file string, lang language.Tag) error {
+	data, err := readFile(rd, file)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkLang(file, lang); err != nil {
+		return err
+	}
+
+	d, err := NewXliffDictionary(data)
+	if err != nil {
+		return fileError(file, err)
+	}
+
+	if d.TargetLang != language.Und && d.TargetLang != lang {
+		return fmt.Errorf("unable to load %s: target language %s of the file does not match %s", file, d.TargetLang, lang)
+	}
+
+	d.file = file
+	p.dictionaries[lang] = d
+
+	return nil
+}
+
+// Unit returns the translation unit of the message.
+func (p *XliffMessageProvider) Unit(lang language.Tag, id string) (*XliffUnit, bool) {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return nil, false
+	}
+
+	return d.Unit(id)
+}
+
+// Description returns notes of the message.
+func (p *XliffMessageProvider) Description(lang language.Tag, id string) string {
+	d, hasDictionary := p.dictionaries[lang]
+	if !hasDictionary {
+		return ""
+	}
+
+	return d.Description(id)
+}
+
+// XliffUnit is a translation unit of a XLIFF file.
+type XliffUnit struct {
+	// ID is resname or id of 1.2 trans-unit, or name or id of 2.x unit
+	ID string
+	// Source and Target are ICU messages, Target is empty if there is no translation
+	Source string
+	Target string
+	// State is state of the target, like translated or final
+	State string
+	Notes []string
+	Line  int
+}
+
+// XliffDictionary is a dictionary of a XLIFF 1.2 or 2.x file, messages are
+// targets of translation units:
+//
+//	<trans-unit id="cart.items">
+//	  <source>You have <ph id="1">{num, plural, one {#</ph> item<ph id="2">} other {#</ph> items<ph id="3">}}</ph></source>
+//	  <target state="translated">...</target>
+//	  <note>Items in the cart</note>
+//	</trans-unit>
+//
+// Text is taken literally and ICU code must be in inline codes, ph, bpt, ept and it
+// with the code as content in XLIFF 1.2, or ph, pc, sc and ec referencing
+// originalData in XLIFF 2.x. The message is built back from text and codes, see parse.JoinSegments.
+// Units without target, or with new, needs-translation or initial state are skipped.
+type XliffDictionary struct {
+	flatDictionary
+	SourceLang language.Tag
+	TargetLang language.Tag
+	units      map[string]*XliffUnit
+}
+
+// Unit returns the translation unit of the message.
+func (d *XliffDictionary) Unit(id string) (*XliffUnit, bool) {
+	u, ok := d.units[id]
+
+	return u, ok
+}
+
+// Description returns notes of the message.
+func (d *XliffDictionary) Description(id string) string {
+	u, ok := d.units[id]
+	if !ok {
+		return ""
+	}
+
+	return strings.Join(u.Notes, "\n")
+}
+
+func NewXliffDictionary(data []byte) (*XliffDictionary, error) {
+	d := &XliffDictionary{
+		flatDictionary: newFlatDictionary(),
+		units:          make(map[string]*XliffUnit),
+	}
+
+	r := &xliffReader{dec: xml.NewDecoder(bytes.NewReader(data)), d: d}
+	if err := r.read(); err != nil {
+		var (
+			lineErr   *lineError
+			syntaxErr *xml.SyntaxError
+		)
+
+		switch {
+		case errors.As(err, &lineErr):
+			return nil, err
+		case errors.As(err, &syntaxErr):
+			return nil, &lineError{Line: syntaxErr.Line, Msg: syntaxErr.Msg}
+		}
+
+		return nil, &lineError{Line: r.line(), Msg: err.Error()}
+	}
+
+	return d, nil
+}
+
+// add adds the unit, units without translations are skipped.
+func (d *XliffDictionary) add(u *XliffUnit) error {
+	if u.ID == "" {
+		return &lineError{Line: u.Line, Msg: "unit without id"}
+	}
+
+	if first, ok := d.units[u.ID]; ok {
+		return &lineError{Line: u.Line, Msg: fmt.Sprintf("duplicate unit %q, first defined on line %d", u.ID, first.Line)}
+	}
+
+	d.units[u.ID] = u
+
+	switch u.State {
+	case "new", "needs-translation", "initial":
+		return nil
+	}
+
+	if u.Target != "" {
+		d.flatMap[u.ID] = u.Target
+		d.lines[u.ID] = u.Line
+	}
+
+	return nil
+}
+
+// xliffReader reads XLIFF documents of both versions, elements are matched by local names.
+type xliffReader struct {
+	dec *xml.Decoder
+	d   *XliffDictionary
+}
+
+func (r *xliffReader) read() error {
+	for {
+		t, err := r.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "xliff":
+			if err := r.langs(start, "srcLang", "trgLang"); err != nil {
+				return err
+			}
+		case "file":
+			if err := r.langs(start, "source-language", "target-language"); err != nil {
+				return err
+			}
+		case "trans-unit":
+			if err := r.transUnit(start); err != nil {
+				return err
+			}
+		case "unit":
+			if err := r.unit(start); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// langs reads languages of the file, all files must have the same languages.
+func (r *xliffReader) langs(start xml.StartElement, srcAttr, trgAttr string) error {
+	for _, l := range []struct {
+		attr string
+		tag  *language.Tag
+	}{{srcAttr, &r.d.SourceLang}, {trgAttr, &r.d.TargetLang}} {
+		value := attr(start, l.attr)
+		if value == "" {
+			continue
+		}
+
+		tag, err := language.Parse(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", l.attr)
+		}
+
+		if *l.tag != language.Und && *l.tag != tag {
+			return errors.Errorf("%s %s differs from %s of another file", l.attr, tag, *l.tag)
+		}
+
+		*l.tag = tag
+	}
+
+	return nil
+}
+
+func (r *xliffReader) line() int {
+	line, _ := r.dec.InputPos()
+
+	return line
+}
+
+// transUnit reads XLIFF 1.2 trans-unit.
+func (r *xliffReader) transUnit(start xml.StartElement) error {
+	u := &XliffUnit{ID: attr(start, "resname"), Line: r.line()}
+	if u.ID == "" {
+		u.ID = attr(start, "id")
+	}
+
+	hasTarget := false
+	for {
+		t, err := r.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.EndElement:
+			if !hasTarget {
+				u.State = "new"
+			}
+
+			return r.d.add(u)
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "source":
+				if u.Source, err = r.message(nil); err != nil {
+					return err
+				}
+			case "target":
+				hasTarget = true
+				u.State = attr(t, "state")
+				if u.Target, err = r.message(nil); err != nil {
+					return err
+				}
+			case "note":
+				if err := r.note(u); err != nil {
+					return err
+				}
+			default:
+				if err := r.dec.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// unit reads XLIFF 2.x unit, its segments are joined.
+func (r *xliffReader) unit(start xml.StartElement) error {
+	u := &XliffUnit{ID: attr(start, "name"), Line: r.line()}
+	if u.ID == "" {
+		u.ID = attr(start, "id")
+	}
+
+	var source, target strings.Builder
+	data := map[string]string{}
+	hasTarget := false
+	for {
+		t, err := r.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.EndElement:
+			if t.Name.Local != "unit" {
+				continue
+			}
+
+			if !hasTarget {
+				u.State = "initial"
+			}
+
+			u.Source, u.Target = source.String(), target.String()
+
+			return r.d.add(u)
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "notes", "originalData":
+				// containers
+			case "note":
+				if err := r.note(u); err != nil {
+					return err
+				}
+			case "data":
+				code, err := r.text()
+				if err != nil {
+					return err
+				}
+
+				data[attr(t, "id")] = code
+			case "segment", "ignorable":
+				if state := attr(t, "state"); state != "" {
+					u.State = state
+				}
+			case "source", "target":
+				msg, err := r.message(data)
+				if err != nil {
+					return err
+				}
+
+				if t.Name.Local == "source" {
+					source.WriteString(msg)
+				} else {
+					hasTarget = true
+					target.WriteString(msg)
+				}
+			default:
+				if err := r.dec.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (r *xliffReader) note(u *XliffUnit) error {
+	note, err := r.text()
+	if err != nil {
+		return err
+	}
+
+	if note = strings.TrimSpace(note); note != "" {
+		u.Notes = append(u.Notes, note)
+	}
+
+	return nil
+}
+
+// text reads text content of the element, nested elements are skipped.
+func (r *xliffReader) text() (string, error) {
+	var b strings.Builder
+	for depth := 1; ; {
+		t, err := r.dec.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := t.(type) {
+		case xml.CharData:
+			if depth == 1 {
+				b.Write(t)
+			}
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				return b.String(), nil
+			}
+		}
+	}
+}
+
+// message reads source or target to ICU message,
+// data is originalData of XLIFF 2.x unit, nil for 1.2.
+func (r *xliffReader) message(data map[string]string) (string, error) {
+	var segs []parse.Segment
+	if err := r.inline(data, &segs); err != nil {
+		return "", err
+	}
+
+	return parse.JoinSegments(segs), nil
+}
+
+// inline reads inline content until the end of the current element.
+func (r *xliffReader) inline(data map[string]string, segs *[]parse.Segment) error {
+	for {
+		t, err := r.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.CharData:
+			*segs = append(*segs, parse.Segment{Text: string(t)})
+		case xml.StartElement:
+			if err := r.inlineElement(t, data, segs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *xliffReader) inlineElement(start xml.StartElement, data map[string]string, segs *[]parse.Segment) error {
+	// code adds original data referenced by ref attribute, or value of equiv attribute
+	code := func(ref, equiv string) error {
+		if id := attr(start, ref); id != "" {
+			c, ok := data[id]
+			if !ok {
+				return &lineError{Line: r.line(), Msg: fmt.Sprintf("no original data %q of <%s>", id, start.Name.Local)}
+			}
+
+			*segs = append(*segs, parse.Segment{Code: c})
+
+			return nil
+		}
+
+		if c := attr(start, equiv); c != "" {
+			*segs = append(*segs, parse.Segment{Code: c})
+
+			return nil
+		}
+
+		return &lineError{Line: r.line(), Msg: fmt.Sprintf("<%s> without original data", start.Name.Local)}
+	}
+
+	switch name := start.Name.Local; {
+	case data == nil && (name == "ph" || name == "bpt" || name == "ept" || name == "it"):
+		// XLIFF 1.2 native code is the content
+		c, err := r.text()
+		if err != nil {
+			return err
+		}
+
+		*segs = append(*segs, parse.Segment{Code: c})
+
+		return nil
+	case data == nil && (name == "x" || name == "bx" || name == "ex"):
+		if err := r.dec.Skip(); err != nil {
+			return err
+		}
+
+		if c := attr(start, "equiv-text"); c != "" {
+			*segs = append(*segs, parse.Segment{Code: c})
+
+			return nil
+		}
+
+		return &lineError{Line: r.line(), Msg: fmt.Sprintf("<%s> without equiv-text, use <ph> with ICU code", name)}
+	case data != nil && (name == "ph" || name == "sc" || name == "ec"):
+		if err := r.dec.Skip(); err != nil {
+			return err
+		}
+
+		return code("dataRef", "equiv")
+	case data != nil && name == "pc":
+		if err := code("dataRefStart", "equivStart"); err != nil {
+			return err
+		}
+
+		if err := r.inline(data, segs); err != nil {
+			return err
+		}
+
+		return code("dataRefEnd", "equivEnd")
+	case data != nil && name == "cp":
+		if err := r.dec.Skip(); err != nil {
+			return err
+		}
+
+		hex, err := strconv.ParseUint(attr(start, "hex"), 16, 32)
+		if err != nil {
+			return &lineError{Line: r.line(), Msg: fmt.Sprintf("invalid <cp> hex %q", attr(start, "hex"))}
+		}
+
+		*segs = append(*segs, parse.Segment{Text: string(rune(hex))})
+
+		return nil
+	default:
+		// g and mrk of 1.2, mrk of 2.x: only text of them is the message
+		return r.inline(data, segs)
+	}
+}
+
+// attr returns value of the attribute without namespace, or empty string.
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+var (
+	_ ListableProvider    = (*XliffMessageProvider)(nil)
+	_ SourceProvider      = (*XliffMessageProvider)(nil)
+	_ DescriptionProvider = (*XliffMessageProvider)(nil)
+)
@@ -0,0 +1,327 @@
+package mf
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+const testXliff12 = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" target-language="de" datatype="plaintext" original="messages">
+    <body>
+      <trans-unit id="1" resname="cart.items">
+        <source>You have <ph id="1">{num, plural, one {#</ph> item<ph id="2">} other {#</ph> items<ph id="3">}}</ph></source>
+        <target state="translated">Sie haben <ph id="1">{num, plural, one {#</ph> Artikel<ph id="2">} other {#</ph> Artikel<ph id="3">}}</ph></target>
+        <note>Items in the cart</note>
+      </trans-unit>
+      <trans-unit id="quote">
+        <source>It's {free}</source>
+        <target state="final"><g id="b">It's</g> {gratis} &amp; <x id="x" equiv-text="{name}"/></target>
+      </trans-unit>
+      <trans-unit id="draft">
+        <source>Draft</source>
+        <target state="needs-translation">Entwurf</target>
+      </trans-unit>
+      <trans-unit id="untranslated">
+        <source>Untranslated</source>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+
+const testXliff20 = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="f1">
+    <unit id="u1" name="hello">
+      <notes>
+        <note>Greeting</note>
+      </notes>
+      <originalData>
+        <data id="d1">{name}</data>
+      </originalData>
+      <segment state="translated">
+        <source>Hello, <ph id="1" dataRef="d1"/>!</source>
+        <target>Hallo, <ph id="1" dataRef="d1"/>!</target>
+      </segment>
+      <ignorable>
+        <source> </source>
+        <target> </target>
+      </ignorable>
+      <segment>
+        <source>Bye</source>
+        <target>Tschüss<cp hex="0021"/></target>
+      </segment>
+    </unit>
+    <unit id="bold">
+      <originalData>
+        <data id="d1">&lt;b&gt;</data>
+        <data id="d2">&lt;/b&gt;</data>
+      </originalData>
+      <segment>
+        <source><pc id="1" dataRefStart="d1" dataRefEnd="d2">Bold</pc></source>
+        <target><pc id="1" dataRefStart="d1" dataRefEnd="d2">Fett {x}</pc></target>
+      </segment>
+    </unit>
+    <unit id="initial">
+      <segment state="initial">
+        <source>Initial</source>
+        <target>Anfang</target>
+      </segment>
+    </unit>
+  </file>
+</xliff>
+`
+
+func TestNewXliffDictionary(t *testing.T) {
+	d, err := NewXliffDictionary([]byte(testXliff12))
+	require.NoError(t, err)
+
+	assert.Equal(t, language.English, d.SourceLang)
+	assert.Equal(t, language.German, d.TargetLang)
+	assert.Equal(t, []string{"cart.items", "quote"}, slices.Collect(d.IDs()))
+
+	msg, err := d.Get("cart.items")
+	require.NoError(t, err)
+	assert.Equal(t, "Sie haben {num, plural, one {# Artikel} other {# Artikel}}", msg)
+
+	msg, err = d.Get("quote")
+	require.NoError(t, err)
+	assert.Equal(t, "It's '{gratis}' & {name}", msg, "text is literal")
+
+	u, ok := d.Unit("cart.items")
+	require.True(t, ok)
+	assert.Equal(t, &XliffUnit{
+		ID:     "cart.items",
+		Source: "You have {num, plural, one {# item} other {# items}}",
+		Target: "Sie haben {num, plural, one {# Artikel} other {# Artikel}}",
+		State:  "translated",
+		Notes:  []string{"Items in the cart"},
+		Line:   5,
+	}, u)
+	assert.Equal(t, "Items in the cart", d.Description("cart.items"))
+
+	u, ok = d.Unit("untranslated")
+	require.True(t, ok)
+	assert.Equal(t, "new", u.State)
+
+	d, err = NewXliffDictionary([]byte(testXliff20))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"bold", "hello"}, slices.Collect(d.IDs()))
+
+	msg, err = d.Get("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo, {name}! Tschüss!", msg)
+
+	msg, err = d.Get("bold")
+	require.NoError(t, err)
+	assert.Equal(t, "<b>Fett '{x}'</b>", msg)
+	assert.Equal(t, "Greeting", d.Description("hello"))
+	assert.Equal(t, 4, d.Line("hello"))
+}
+
+func TestNewXliffDictionary_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"syntax", "<xliff>\n<file>\n</xliff>", "line 3: element <file> closed by </xliff>"},
+		{"duplicate", `<xliff><file><body>
+<trans-unit id="a"><source>a</source></trans-unit>
+<trans-unit id="a"><source>a</source></trans-unit>
+</body></file></xliff>`, `line 3: duplicate unit "a", first defined on line 2`},
+		{"no id", `<xliff><file><body><trans-unit><source>a</source></trans-unit></body></file></xliff>`, "line 1: unit without id"},
+		{"x without equiv", `<xliff><file><body><trans-unit id="a"><source><x id="1"/></source></trans-unit></body></file></xliff>`, "line 1: <x> without equiv-text, use <ph> with ICU code"},
+		{"no data", `<xliff version="2.0"><file><unit id="a"><segment><source><ph id="1" dataRef="d1"/></source></segment></unit></file></xliff>`, `line 1: no original data "d1" of <ph>`},
+		{"languages", `<xliff><file target-language="de"/><file target-language="fr"/></xliff>`, "line 1: target-language fr differs from de of another file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewXliffDictionary([]byte(tt.in))
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewXliffMessageProvider(t *testing.T) {
+	p, err := NewXliffMessageProvider(fstest.MapFS{
+		"messages.de.xlf":    {Data: []byte(testXliff12)},
+		"other/app.fr.xliff": {Data: []byte(`<xliff version="2.0" trgLang="fr"><file><unit id="a"><segment><source>A</source><target>À</target></segment></unit></file></xliff>`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.German, language.French}, p.Languages())
+	assert.Equal(t, "Items in the cart", p.Description(language.German, "cart.items"))
+
+	file, line, ok := p.Source(language.German, "quote")
+	assert.True(t, ok)
+	assert.Equal(t, "messages.de.xlf", file)
+	assert.Equal(t, 10, line)
+
+	_, err = NewXliffMessageProvider(fstest.MapFS{"messages.fr.xlf": {Data: []byte(testXliff12)}})
+	require.EqualError(t, err, "unable to load messages.fr.xlf: target language de of the file does not match fr")
+
+	_, err = NewXliffMessageProvider(fstest.MapFS{"messages.de.xlf": {Data: []byte("<xliff>\n<")}})
+	require.ErrorContains(t, err, "messages.de.xlf:2: ")
+
+	b, err := NewBundle(WithXliffProvider(fstest.MapFS{"messages.de.xlf": {Data: []byte(testXliff12)}}))
+	require.NoError(t, err)
+	assert.Equal(t, "Sie haben 2 Artikel", b.Translator("de").Trans("cart.items", Arg("num", 2)))
+}
+
+func testXliffProvider(t *testing.T) *YamlMessageProvider {
+	t.Helper()
+
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`
+cart:
+  # Items in the cart
+  items: "{num, plural, one {# item} other {# items}}"
+hello: "Hello, {name} & {name}!"
+"to do": Don't <b>
+mf2: !mf2 "{$x}"
+`)},
+		"messages.de.yaml": {Data: []byte(`
+hello: "{name}, hallo!"
+`)},
+	})
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestWriteXliff(t *testing.T) {
+	p := testXliffProvider(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteXliff(&buf, p, language.English, language.German, Xliff12))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" target-language="de" datatype="plaintext" original="messages">
+    <body>
+      <trans-unit id="cart.items">
+        <source><ph id="1">{num, plural, one {#</ph> item<ph id="2">} other {#</ph> items<ph id="3">}}</ph></source>
+        <target state="new"></target>
+        <note>Items in the cart</note>
+      </trans-unit>
+      <trans-unit id="hello">
+        <source>Hello, <ph id="1">{name}</ph> &amp; <ph id="2">{name}</ph>!</source>
+        <target state="translated"><ph id="1">{name}</ph>, hallo!</target>
+      </trans-unit>
+      <trans-unit id="mf2">
+        <source><ph id="1">{$x}</ph></source>
+        <target state="new"></target>
+      </trans-unit>
+      <trans-unit id="to do">
+        <source>Don't &lt;b&gt;</source>
+        <target state="new"></target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteXliff(&buf, p, language.English, language.German, Xliff20))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="messages">
+    <unit id="cart.items" name="cart.items">
+      <notes>
+        <note>Items in the cart</note>
+      </notes>
+      <originalData>
+        <data id="d1">{num, plural, one {#</data>
+        <data id="d2">} other {#</data>
+        <data id="d3">}}</data>
+      </originalData>
+      <segment state="initial">
+        <source><ph id="1" dataRef="d1"/> item<ph id="2" dataRef="d2"/> items<ph id="3" dataRef="d3"/></source>
+      </segment>
+    </unit>
+    <unit id="hello" name="hello">
+      <originalData>
+        <data id="d1">{name}</data>
+      </originalData>
+      <segment state="translated">
+        <source>Hello, <ph id="1" dataRef="d1"/> &amp; <ph id="2" dataRef="d1"/>!</source>
+        <target><ph id="1" dataRef="d1"/>, hallo!</target>
+      </segment>
+    </unit>
+    <unit id="mf2" name="mf2">
+      <originalData>
+        <data id="d1">{$x}</data>
+      </originalData>
+      <segment state="initial">
+        <source><ph id="1" dataRef="d1"/></source>
+      </segment>
+    </unit>
+    <unit id="u1" name="to do">
+      <segment state="initial">
+        <source>Don't &lt;b&gt;</source>
+      </segment>
+    </unit>
+  </file>
+</xliff>
+`, buf.String())
+
+	require.EqualError(t, WriteXliff(&buf, p, language.English, language.German, "1.1"), `unsupported xliff version "1.1"`)
+}
+
+func TestWriteXliff_RoundTrip(t *testing.T) {
+	p := testXliffProvider(t)
+
+	for _, version := range []XliffVersion{Xliff12, Xliff20} {
+		t.Run(string(version), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteXliff(&buf, p, language.English, language.English, version))
+
+			x, err := NewXliffMessageProvider(fstest.MapFS{"messages.en.xlf": {Data: buf.Bytes()}})
+			require.NoError(t, err)
+
+			for id := range p.IDs(language.English) {
+				want, err := p.Get(language.English, id)
+				require.NoError(t, err)
+
+				got, err := x.Get(language.English, id)
+				require.NoError(t, err, id)
+				if m, err := parse.Parse(want); err == nil {
+					want = m.String()
+				}
+
+				assert.Equal(t, want, got, id)
+			}
+
+			assert.Equal(t, "Items in the cart", x.Description(language.English, "cart.items"))
+		})
+	}
+}
+
+func TestWriteXliff_Choice(t *testing.T) {
+	p, err := NewYamlMessageProvider(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte(`files: "{n, choice, 0#no files|1#one file|1<{n, number} files '|' all}"`)},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteXliff(&buf, p, language.English, language.English, Xliff12))
+	assert.Contains(t, buf.String(), `<source><ph id="1">{n, choice, 0#</ph>no files<ph id="2">|1#</ph>one file`+
+		`<ph id="3">|1&lt;{n, number}</ph> files | all<ph id="4">}</ph></source>`)
+
+	x, err := NewXliffMessageProvider(fstest.MapFS{"messages.en.xlf": {Data: buf.Bytes()}})
+	require.NoError(t, err)
+
+	got, err := x.Get(language.English, "files")
+	require.NoError(t, err)
+	assert.Equal(t, "{n, choice, 0#no files|1#one file|1<{n, number} files '|' all}", got)
+}
@@ -0,0 +1,320 @@
+package mf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"golang.org/x/text/language"
+)
+
+// XliffVersion is a version of XLIFF documents written by WriteXliff.
+type XliffVersion string
+
+const (
+	Xliff12 XliffVersion = "1.2"
+	Xliff20 XliffVersion = "2.0"
+)
+
+// WriteXliff writes messages of srcLang with their translations to trgLang as XLIFF
+// document for translation tools. ICU code of messages is protected from translators
+// with inline ph elements, text is translatable, see parse.Segments. Messages,
+// which are not ICU messages, are protected as a whole.
+//
+// Translated messages have translated state, others have new state in XLIFF 1.2,
+// or initial state in XLIFF 2.0. Descriptions of source messages are written as notes,
+// if the provider is a DescriptionProvider. Translated document is loaded with XliffMessageProvider.
+func WriteXliff(w io.Writer, p ListableProvider, srcLang, trgLang language.Tag, version XliffVersion) error {
+	var xw xliffWriter
+	switch version {
+	case Xliff12:
+		xw = &xliff12Writer{}
+	case Xliff20:
+		xw = &xliff20Writer{}
+	default:
+		return fmt.Errorf("unsupported xliff version %q", version)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	xw.open(bw, srcLang, trgLang)
+
+	descriptions, _ := p.(DescriptionProvider)
+	ids := slices.Collect(p.IDs(srcLang))
+	unitIDs := xliffUnitIDs(ids)
+	for i, id := range ids {
+		source, err := p.Get(srcLang, id)
+		if err != nil {
+			return err
+		}
+
+		u := xliffUnit{id: id, unitID: unitIDs[i], codes: newXliffCodes()}
+		u.source = u.codes.inline(xliffSegments(p, srcLang, id, source))
+
+		if target, err := p.Get(trgLang, id); err == nil {
+			u.translated = true
+			u.target = u.codes.inline(xliffSegments(p, trgLang, id, target))
+		}
+
+		if descriptions != nil {
+			u.note = descriptions.Description(srcLang, id)
+		}
+
+		xw.unit(bw, &u)
+	}
+
+	xw.close(bw)
+
+	return bw.Flush()
+}
+
+// xliffSegments returns text and code of the message, non ICU messages are code.
+func xliffSegments(p MessageProvider, lang language.Tag, id, msg string) []parse.Segment {
+	if messageSyntax(p, lang, id) != SyntaxICU {
+		return []parse.Segment{{Code: msg}}
+	}
+
+	m, err := parse.Parse(msg)
+	if err != nil {
+		return []parse.Segment{{Code: msg}}
+	}
+
+	return parse.Segments(m)
+}
+
+// xliffUnitIDs returns ids for XLIFF 2.0 units, ids must be NMTOKEN there.
+// Message ids, which are not, get generated ids, message id is the name of unit anyway.
+func xliffUnitIDs(ids []string) []string {
+	used := map[string]bool{}
+	for _, id := range ids {
+		if isNMToken(id) {
+			used[id] = true
+		}
+	}
+
+	unitIDs := make([]string, len(ids))
+	n := 0
+	for i, id := range ids {
+		if isNMToken(id) {
+			unitIDs[i] = id
+
+			continue
+		}
+
+		for unitIDs[i] == "" || used[unitIDs[i]] {
+			n++
+			unitIDs[i] = "u" + strconv.Itoa(n)
+		}
+
+		used[unitIDs[i]] = true
+	}
+
+	return unitIDs
+}
+
+func isNMToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(".-_:", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type xliffUnit struct {
+	id, unitID     string
+	source, target []xliffInline
+	translated     bool
+	note           string
+	codes          *xliffCodes
+}
+
+// xliffInline is text, or code with ph id and data id.
+type xliffInline struct {
+	parse.Segment
+	id, dataRef string
+}
+
+// xliffCodes numbers codes of a unit, same codes of source and target share ids.
+type xliffCodes struct {
+	// data are codes by data ids, d1 is the first
+	data []string
+	refs map[string]string
+	// ids are ph ids of code occurrences in source
+	ids  map[string][]string
+	next int
+}
+
+func newXliffCodes() *xliffCodes {
+	return &xliffCodes{refs: map[string]string{}, ids: map[string][]string{}}
+}
+
+// inline numbers codes of source, then of target segments,
+// n-th occurrence of a code in target gets id of n-th occurrence in source.
+func (c *xliffCodes) inline(segs []parse.Segment) []xliffInline {
+	var (
+		inline []xliffInline
+		seen   = map[string]int{}
+	)
+
+	for _, s := range segs {
+		if s.Code == "" {
+			inline = append(inline, xliffInline{Segment: s})
+
+			continue
+		}
+
+		ref, ok := c.refs[s.Code]
+		if !ok {
+			c.data = append(c.data, s.Code)
+			ref = "d" + strconv.Itoa(len(c.data))
+			c.refs[s.Code] = ref
+		}
+
+		n := seen[s.Code]
+		seen[s.Code]++
+		if n >= len(c.ids[s.Code]) {
+			c.next++
+			c.ids[s.Code] = append(c.ids[s.Code], strconv.Itoa(c.next))
+		}
+
+		inline = append(inline, xliffInline{Segment: s, id: c.ids[s.Code][n], dataRef: ref})
+	}
+
+	return inline
+}
+
+type xliffWriter interface {
+	open(w *bufio.Writer, srcLang, trgLang language.Tag)
+	unit(w *bufio.Writer, u *xliffUnit)
+	close(w *bufio.Writer)
+}
+
+type xliff12Writer struct{}
+
+func (*xliff12Writer) open(w *bufio.Writer, srcLang, trgLang language.Tag) {
+	w.WriteString("<xliff version=\"1.2\" xmlns=\"urn:oasis:names:tc:xliff:document:1.2\">\n")
+	fmt.Fprintf(w, "  <file source-language=%s target-language=%s datatype=\"plaintext\" original=\"messages\">\n", xmlAttr(srcLang.String()), xmlAttr(trgLang.String()))
+	w.WriteString("    <body>\n")
+}
+
+func (*xliff12Writer) unit(w *bufio.Writer, u *xliffUnit) {
+	fmt.Fprintf(w, "      <trans-unit id=%s>\n", xmlAttr(u.id))
+
+	inline := func(inline []xliffInline) {
+		for _, s := range inline {
+			if s.Code == "" {
+				w.WriteString(xmlText(s.Text))
+
+				continue
+			}
+
+			fmt.Fprintf(w, "<ph id=%s>%s</ph>", xmlAttr(s.id), xmlText(s.Code))
+		}
+	}
+
+	w.WriteString("        <source>")
+	inline(u.source)
+	w.WriteString("</source>\n")
+
+	if u.translated {
+		w.WriteString("        <target state=\"translated\">")
+		inline(u.target)
+		w.WriteString("</target>\n")
+	} else {
+		w.WriteString("        <target state=\"new\"></target>\n")
+	}
+
+	if u.note != "" {
+		fmt.Fprintf(w, "        <note>%s</note>\n", xmlText(u.note))
+	}
+
+	w.WriteString("      </trans-unit>\n")
+}
+
+func (*xliff12Writer) close(w *bufio.Writer) {
+	w.WriteString("    </body>\n  </file>\n</xliff>\n")
+}
+
+type xliff20Writer struct{}
+
+func (*xliff20Writer) open(w *bufio.Writer, srcLang, trgLang language.Tag) {
+	fmt.Fprintf(w, "<xliff xmlns=\"urn:oasis:names:tc:xliff:document:2.0\" version=\"2.0\" srcLang=%s trgLang=%s>\n", xmlAttr(srcLang.String()), xmlAttr(trgLang.String()))
+	w.WriteString("  <file id=\"messages\">\n")
+}
+
+func (*xliff20Writer) unit(w *bufio.Writer, u *xliffUnit) {
+	fmt.Fprintf(w, "    <unit id=%s name=%s>\n", xmlAttr(u.unitID), xmlAttr(u.id))
+
+	if u.note != "" {
+		fmt.Fprintf(w, "      <notes>\n        <note>%s</note>\n      </notes>\n", xmlText(u.note))
+	}
+
+	if len(u.codes.data) > 0 {
+		w.WriteString("      <originalData>\n")
+		for i, code := range u.codes.data {
+			fmt.Fprintf(w, "        <data id=\"d%d\">%s</data>\n", i+1, xmlText(code))
+		}
+
+		w.WriteString("      </originalData>\n")
+	}
+
+	inline := func(inline []xliffInline) {
+		for _, s := range inline {
+			if s.Code == "" {
+				w.WriteString(xmlText(s.Text))
+
+				continue
+			}
+
+			fmt.Fprintf(w, "<ph id=%s dataRef=%s/>", xmlAttr(s.id), xmlAttr(s.dataRef))
+		}
+	}
+
+	state := "initial"
+	if u.translated {
+		state = "translated"
+	}
+
+	fmt.Fprintf(w, "      <segment state=%q>\n", state)
+	w.WriteString("        <source>")
+	inline(u.source)
+	w.WriteString("</source>\n")
+
+	if u.translated {
+		w.WriteString("        <target>")
+		inline(u.target)
+		w.WriteString("</target>\n")
+	}
+
+	w.WriteString("      </segment>\n    </unit>\n")
+}
+
+func (*xliff20Writer) close(w *bufio.Writer) {
+	w.WriteString("  </file>\n</xliff>\n")
+}
+
+var (
+	xmlTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	xmlAttrReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+// xmlText escapes text content, line breaks are kept.
+func xmlText(s string) string {
+	return xmlTextReplacer.Replace(s)
+}
+
+// xmlAttr returns quoted attribute value.
+func xmlAttr(s string) string {
+	return `"` + xmlAttrReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,95 @@
+package parse
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ChoiceLimit parses case name of choice, like 0#, 1< or -∞≤.
+// strict is true for < limits, which do not match the limit itself.
+func ChoiceLimit(name string) (limit float64, strict bool, err error) {
+	var num string
+	switch {
+	case strings.HasSuffix(name, "#"):
+		num = strings.TrimSuffix(name, "#")
+	case strings.HasSuffix(name, "≤"):
+		num = strings.TrimSuffix(name, "≤")
+	case strings.HasSuffix(name, "<"):
+		num, strict = strings.TrimSuffix(name, "<"), true
+	default:
+		return 0, false, fmt.Errorf("invalid choice case %s", name)
+	}
+
+	switch num {
+	case "∞":
+		return math.Inf(1), strict, nil
+	case "-∞":
+		return math.Inf(-1), strict, nil
+	}
+
+	limit, err = strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid choice case %s", name)
+	}
+
+	return limit, strict, nil
+}
+
+// ChoiceToPlural rewrites legacy choice expressions of the message to plural, in place:
+//
+//	{n, choice, 0#no files|1#one file|1<{n} files}
+//	{n, plural, =0 {no files} =1 {one file} other {{n} files}}
+//
+// Every case but the last must match exactly one integer starting from 0,
+// the last case becomes other. So results differ only for negative and
+// fractional numbers. Choices with ranges, like 0#none|1#few|5#many,
+// are left as is, ChoiceToPlural returns their number.
+func ChoiceToPlural(m *Message) int {
+	left := 0
+	Rewrite(m, func(n Node) Node {
+		e, ok := n.(*Expr)
+		if !ok || e.Func != "choice" {
+			return n
+		}
+
+		if cases, ok := choicePluralCases(e.Cases); ok {
+			e.Func, e.Cases = "plural", cases
+		} else {
+			left++
+		}
+
+		return n
+	})
+
+	return left
+}
+
+func choicePluralCases(cases []*Case) ([]*Case, bool) {
+	if len(cases) == 0 {
+		return nil, false
+	}
+
+	plural := make([]*Case, 0, len(cases))
+	next := 0.0
+	for i, c := range cases[:len(cases)-1] {
+		limit, strict, err := ChoiceLimit(c.Name)
+		if err != nil || strict || limit != next {
+			return nil, false
+		}
+
+		// the next case must start right after the limit
+		nextLimit, nextStrict, err := ChoiceLimit(cases[i+1].Name)
+		if err != nil || (nextLimit != limit+1 || nextStrict) && (nextLimit != limit || !nextStrict) {
+			return nil, false
+		}
+
+		plural = append(plural, &Case{Name: fmt.Sprintf("=%d", int(limit)), Message: c.Message})
+		next = limit + 1
+	}
+
+	last := cases[len(cases)-1]
+
+	return append(plural, &Case{Name: "other", Message: last.Message}), true
+}
@@ -0,0 +1,74 @@
+package parse
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChoiceLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  float64
+		strict bool
+	}{
+		{"0#", 0, false},
+		{"1.5<", 1.5, true},
+		{"2≤", 2, false},
+		{"-∞#", math.Inf(-1), false},
+		{"∞<", math.Inf(1), true},
+	}
+
+	for _, tt := range tests {
+		limit, strict, err := ChoiceLimit(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.limit, limit, tt.name)
+		assert.Equal(t, tt.strict, strict, tt.name)
+	}
+
+	_, _, err := ChoiceLimit("other")
+	assert.Error(t, err)
+}
+
+func TestChoiceToPlural(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		left int
+	}{
+		{
+			"{n, choice, 0#no files|1#one file|1<{n, number} files}",
+			"{n, plural, =0 {no files} =1 {one file} other {{n, number} files}}",
+			0,
+		},
+		{
+			"{n, choice, 0#none|1#one|2#two|3## and '|'}",
+			"{n, plural, =0 {none} =1 {one} =2 {two} other {'#' and |}}",
+			0,
+		},
+		{"{n, choice, 0#{n}}", "{n, plural, other {{n}}}", 0},
+		{
+			"{n, choice, 0#none|1#few|5#many} {m, choice, 1#one|1<many}",
+			"{n, choice, 0#none|1#few|5#many} {m, choice, 1#one|1<many}",
+			2,
+		},
+		{"{n, choice, -1#neg|0#zero|0<many}", "{n, choice, -1#neg|0#zero|0<many}", 1},
+		{
+			"{g, select, a {{n, choice, 0#none|0<{n}}} other {}}",
+			"{g, select, a {{n, plural, =0 {none} other {{n}}}} other {}}",
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			msg, err := Parse(tt.in)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.left, ChoiceToPlural(msg))
+			assert.Equal(t, tt.want, msg.String())
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package parse
+
+import "fmt"
+
+// Position of a token in a message, Line and Column start from 1.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SyntaxError is returned when a message could not be parsed.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+	// Suggestion is an obvious fix, if any.
+	Suggestion string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%s: %s; %s", e.Pos, e.Msg, e.Suggestion)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
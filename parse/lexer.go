@@ -0,0 +1,346 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+
+	// message text
+	tokEscaped   // ''
+	tokQuoted    // '{...', value is the unquoted text
+	tokQuote     // '
+	tokString    // [^'{]+
+	tokExprStart // {
+
+	// inside braces
+	tokWhitespace // \s+, elided
+	tokPunct      // , or :, elided
+	tokInt        // \d+
+	tokIdent      // \w+
+	tokCase       // =\d+
+	tokExprEnd    // }
+	tokSubStart   // {
+
+	// case message
+	tokSubEscaped // ''
+	tokSubQuote   // '
+	tokOctothorpe // #, only in plural and selectordinal
+	tokSubString  // [^{}']+, # is excluded in plural and | in choice
+	tokSubEnd     // }
+
+	// legacy choice
+	tokChoiceLimit // -?\d+(\.\d+)? or ∞
+	tokChoiceSep   // #, < or ≤
+	tokChoicePipe  // |
+
+	// invalid rune inside braces, only in lenient mode
+	tokInvalid
+)
+
+type token struct {
+	typ    tokenType
+	val    string
+	offset int
+}
+
+// elided tokens are skipped unless the grammar asks for them explicitly.
+func (t token) elided() bool {
+	return t.typ == tokWhitespace || t.typ == tokPunct
+}
+
+type lexerState int
+
+const (
+	stateText lexerState = iota
+	stateExpr
+	stateSub
+	stateChoice
+)
+
+type lexerFrame struct {
+	state lexerState
+	// header is idents of expression before its first case,
+	// argument names followed by functions
+	header []string
+	cases  bool
+	// special is # for case messages of plural and selectordinal,
+	// and | for messages of choice
+	special byte
+}
+
+// lex splits message to tokens, the last token is always tokEOF.
+// Invalid input inside braces is an error, or a tokInvalid rune if lenient.
+//
+// Apostrophes follow ICU DOUBLE_OPTIONAL mode: a doubled apostrophe is literal,
+// an apostrophe before {, } or # in plural starts quoted text up to the next
+// single apostrophe or the end of message, any other apostrophe is literal.
+func lex(src string, lenient bool) ([]token, error) {
+	tokens := make([]token, 0, 8)
+	stack := []lexerFrame{{state: stateText}}
+
+	for pos := 0; pos < len(src); {
+		var (
+			typ tokenType
+			n   int
+			val string
+		)
+
+		top := &stack[len(stack)-1]
+		switch top.state {
+		case stateText:
+			typ, n, val = lexText(src[pos:])
+			if typ == tokExprStart {
+				stack = append(stack, lexerFrame{state: stateExpr})
+			}
+		case stateExpr:
+			typ, n = lexExpr(src[pos:])
+			switch typ {
+			case tokIdent:
+				if !top.cases {
+					top.header = append(top.header, src[pos:pos+n])
+				}
+			case tokPunct:
+				// {n, choice, 0#none|1#one}
+				if !top.cases && len(top.header) == 2 && top.header[1] == "choice" && src[pos] == ',' {
+					top.state, top.cases = stateChoice, true
+				}
+			case tokSubStart:
+				if !top.cases {
+					top.cases = true
+					if hasPlural(top.header) {
+						top.special = '#'
+					}
+				}
+
+				stack = append(stack, lexerFrame{state: stateSub, special: top.special})
+			case tokExprEnd:
+				stack = stack[:len(stack)-1]
+			}
+		case stateSub:
+			typ, n, val = lexSub(src[pos:], top.special)
+			switch typ {
+			case tokExprStart:
+				stack = append(stack, lexerFrame{state: stateExpr})
+			case tokChoicePipe:
+				stack = stack[:len(stack)-1]
+			case tokSubEnd:
+				stack = stack[:len(stack)-1]
+				// the last choice message ends with the expression
+				if top.special == '|' {
+					typ = tokExprEnd
+					stack = stack[:len(stack)-1]
+				}
+			}
+		case stateChoice:
+			typ, n = lexChoice(src[pos:])
+			switch typ {
+			case tokChoiceSep:
+				stack = append(stack, lexerFrame{state: stateSub, special: '|'})
+			case tokExprEnd:
+				stack = stack[:len(stack)-1]
+			}
+		}
+
+		if n == 0 && lenient {
+			_, size := utf8.DecodeRuneInString(src[pos:])
+			typ, n = tokInvalid, size
+		}
+
+		if n == 0 {
+			return nil, &SyntaxError{
+				Pos: positionAt(src, pos),
+				Msg: fmt.Sprintf("lexer: invalid input text %q", sample(src[pos:])),
+			}
+		}
+
+		if typ != tokQuoted {
+			val = src[pos : pos+n]
+		}
+
+		tokens = append(tokens, token{typ: typ, val: val, offset: pos})
+		pos += n
+	}
+
+	return append(tokens, token{typ: tokEOF, offset: len(src)}), nil
+}
+
+func lexText(s string) (tokenType, int, string) {
+	switch {
+	case strings.HasPrefix(s, "''"):
+		return tokEscaped, 2, ""
+	case len(s) > 1 && s[0] == '\'' && (s[1] == '{' || s[1] == '}'):
+		n, val, _ := lexQuoted(s)
+
+		return tokQuoted, n, val
+	case s[0] == '\'':
+		return tokQuote, 1, ""
+	case s[0] == '{':
+		return tokExprStart, 1, ""
+	default:
+		return tokString, span(s, func(c byte) bool { return c != '\'' && c != '{' }), ""
+	}
+}
+
+func lexExpr(s string) (tokenType, int) {
+	switch c := s[0]; {
+	case isSpace(c):
+		return tokWhitespace, span(s, isSpace)
+	case c == ',' || c == ':':
+		return tokPunct, 1
+	case isDigit(c):
+		return tokInt, span(s, isDigit)
+	case isWord(c):
+		return tokIdent, span(s, isWord)
+	case c == '=':
+		if n := span(s[1:], isDigit); n > 0 {
+			return tokCase, n + 1
+		}
+
+		return tokEOF, 0
+	case c == '}':
+		return tokExprEnd, 1
+	case c == '{':
+		return tokSubStart, 1
+	default:
+		return tokEOF, 0
+	}
+}
+
+// lexSub lexes case message, special is # in plural, | in choice or 0.
+func lexSub(s string, special byte) (tokenType, int, string) {
+	switch {
+	case strings.HasPrefix(s, "''"):
+		return tokSubEscaped, 2, ""
+	case len(s) > 1 && s[0] == '\'' && (s[1] == '{' || s[1] == '}' || (special != 0 && s[1] == special)):
+		n, val, _ := lexQuoted(s)
+
+		return tokQuoted, n, val
+	case s[0] == '\'':
+		return tokSubQuote, 1, ""
+	case s[0] == '#' && special == '#':
+		return tokOctothorpe, 1, ""
+	case s[0] == '|' && special == '|':
+		return tokChoicePipe, 1, ""
+	case s[0] == '{':
+		return tokExprStart, 1, ""
+	case s[0] == '}':
+		return tokSubEnd, 1, ""
+	default:
+		return tokSubString, span(s, func(c byte) bool {
+			return c != '{' && c != '}' && c != '\'' && (special == 0 || c != special)
+		}), ""
+	}
+}
+
+// lexChoice lexes limits of choice, messages are lexed with lexSub.
+func lexChoice(s string) (tokenType, int) {
+	switch c := s[0]; {
+	case isSpace(c):
+		return tokWhitespace, span(s, isSpace)
+	case c == '#' || c == '<':
+		return tokChoiceSep, 1
+	case strings.HasPrefix(s, "≤"):
+		return tokChoiceSep, len("≤")
+	case c == '}':
+		return tokExprEnd, 1
+	}
+
+	n := 0
+	if s[0] == '-' {
+		n++
+	}
+
+	if strings.HasPrefix(s[n:], "∞") {
+		return tokChoiceLimit, n + len("∞")
+	}
+
+	digits := span(s[n:], isDigit)
+	if digits == 0 {
+		return tokEOF, 0
+	}
+
+	n += digits
+	if len(s) > n+1 && s[n] == '.' && isDigit(s[n+1]) {
+		n += 1 + span(s[n+1:], isDigit)
+	}
+
+	return tokChoiceLimit, n
+}
+
+// lexQuoted reads quoted text starting with an apostrophe,
+// it returns length of the quoted text and its value.
+// Unclosed quote runs to the end of message.
+func lexQuoted(s string) (int, string, bool) {
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+
+			continue
+		}
+
+		// '' inside quoted text is an apostrophe too
+		if i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+
+			continue
+		}
+
+		return i + 1, b.String(), true
+	}
+
+	return len(s), b.String(), false
+}
+
+// hasPlural reports if expression header has plural or selectordinal function.
+// Header is argument names followed by a function per name, like
+// "count plural" or "gender count select plural", case names could follow.
+func hasPlural(header []string) bool {
+	for i := 1; i < len(header); i++ {
+		if !isSelectFunc(header[i]) {
+			continue
+		}
+
+		for _, fn := range header[i:min(2*i, len(header))] {
+			if fn == "plural" || fn == "selectordinal" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
+
+func isSelectFunc(fn string) bool {
+	return fn == "select" || fn == "plural" || fn == "selectordinal"
+}
+
+// span returns length of the prefix of s with all bytes matching f.
+func span(s string, f func(c byte) bool) int {
+	n := 0
+	for n < len(s) && f(s[n]) {
+		n++
+	}
+
+	return n
+}
+
+// sample returns the beginning of s for error messages.
+func sample(s string) string {
+	r := []rune(s)
+	if len(r) > 16 {
+		return string(r[:16]) + "..."
+	}
+
+	return string(r)
+}
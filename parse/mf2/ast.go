@@ -0,0 +1,104 @@
+// Package mf2 parses Unicode MessageFormat 2 messages.
+//
+// Use message.BuildMF2 to compile a parsed message.
+package mf2
+
+// Message is a parsed message. Messages without .match have Pattern,
+// messages with .match have Selectors and Variants instead.
+type Message struct {
+	Declarations []*Declaration
+	Pattern      Pattern
+	// Selectors are names of .match variables, without $.
+	Selectors []string
+	Variants  []*Variant
+}
+
+// Declaration is .input {$name ...} or .local $name = {...}.
+type Declaration struct {
+	Local bool
+	// Name of the variable, without $.
+	Name  string
+	Value *Expression
+}
+
+// Variant is a pattern selected by keys, one key per selector.
+type Variant struct {
+	Keys    []Key
+	Pattern Pattern
+}
+
+// Key is a literal or the catch-all key *.
+type Key struct {
+	Value string
+	Star  bool
+}
+
+func (k Key) String() string {
+	if k.Star {
+		return "*"
+	}
+
+	return k.Value
+}
+
+// Pattern is text with placeholders.
+type Pattern []*Part
+
+// Part is either text, an expression or markup.
+type Part struct {
+	Text       string
+	Expression *Expression
+	Markup     *Markup
+}
+
+// Expression is a placeholder like {$count :number}, {|text|} or {:fn}.
+// Operand or Function could be nil, but not both.
+type Expression struct {
+	Operand    *Operand
+	Function   *Function
+	Attributes []*Attribute
+}
+
+// Operand is a variable or a literal, Variable is empty for literals.
+type Operand struct {
+	// Variable name, without $.
+	Variable string
+	Literal  string
+}
+
+// Function is an annotation like :number select=ordinal.
+type Function struct {
+	Name    string
+	Options []*Option
+}
+
+// Option is name=value of a function or markup.
+type Option struct {
+	Name  string
+	Value Operand
+}
+
+// Attribute is @name or @name=literal, attributes do not affect formatting.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+type MarkupKind int
+
+const (
+	// MarkupOpen is {#name}.
+	MarkupOpen MarkupKind = iota
+	// MarkupClose is {/name}.
+	MarkupClose
+	// MarkupStandalone is {#name/}.
+	MarkupStandalone
+)
+
+// Markup is an open, close or standalone markup placeholder.
+type Markup struct {
+	Kind       MarkupKind
+	Name       string
+	Options    []*Option
+	Attributes []*Attribute
+}
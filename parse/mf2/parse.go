@@ -0,0 +1,827 @@
+package mf2
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fullpipe/icu-mf/parse"
+)
+
+// Parse parses MessageFormat 2 message, errors are returned as *parse.SyntaxError.
+// Besides syntax errors, it reports data model errors of the spec:
+// duplicate declarations and options, selectors without annotation,
+// variants with wrong number of keys, duplicate and missing fallback variants.
+func Parse(msg string) (*Message, error) {
+	p := &parser{
+		src:       msg,
+		declared:  map[string]bool{},
+		used:      map[string]bool{},
+		annotated: map[string]bool{},
+	}
+
+	return p.message()
+}
+
+type parser struct {
+	src string
+	pos int
+
+	// variables declared and used in declarations so far
+	declared map[string]bool
+	used     map[string]bool
+	// annotated variables have a function, they could be selectors
+	annotated map[string]bool
+}
+
+func (p *parser) errorf(offset int, format string, args ...any) error {
+	return &parse.SyntaxError{
+		Pos: position(p.src, offset),
+		Msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// unexpected reports the next character or the end of message.
+func (p *parser) unexpected(expected string) error {
+	if p.eof() {
+		return p.errorf(p.pos, "unexpected end of message, expected %s", expected)
+	}
+
+	return p.errorf(p.pos, "unexpected %q, expected %s", p.peekRune(), expected)
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *parser) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+
+	return r
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func (p *parser) expect(s string) error {
+	if !p.hasPrefix(s) {
+		return p.unexpected(fmt.Sprintf("%q", s))
+	}
+
+	p.pos += len(s)
+
+	return nil
+}
+
+// spaces skips optional whitespace and bidi marks, it reports if there was whitespace.
+func (p *parser) spaces() bool {
+	found := false
+	for !p.eof() {
+		r, n := utf8.DecodeRuneInString(p.src[p.pos:])
+		switch {
+		case isSpace(r):
+			found = true
+		case isBidi(r):
+		default:
+			return found
+		}
+
+		p.pos += n
+	}
+
+	return found
+}
+
+// keyword consumes keyword like .input, if it is not a prefix of a longer name.
+func (p *parser) keyword(kw string) bool {
+	if !p.hasPrefix(kw) {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos+len(kw):])
+	if isNameChar(r) {
+		return false
+	}
+
+	p.pos += len(kw)
+
+	return true
+}
+
+// message parses simple message, which is a pattern,
+// or complex message that starts with a declaration or a quoted pattern.
+func (p *parser) message() (*Message, error) {
+	p.spaces()
+	if !p.hasPrefix(".") && !p.hasPrefix("{{") {
+		// leading whitespace is a part of simple message
+		p.pos = 0
+
+		pattern, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.eof() {
+			return nil, p.errorf(p.pos, "unexpected '}', use \\} for a literal brace")
+		}
+
+		return &Message{Pattern: pattern}, nil
+	}
+
+	m := &Message{}
+	for {
+		p.spaces()
+
+		switch start := p.pos; {
+		case p.keyword(".input"):
+			d, err := p.input(start)
+			if err != nil {
+				return nil, err
+			}
+
+			m.Declarations = append(m.Declarations, d)
+		case p.keyword(".local"):
+			d, err := p.local(start)
+			if err != nil {
+				return nil, err
+			}
+
+			m.Declarations = append(m.Declarations, d)
+		case p.keyword(".match"):
+			if err := p.matcher(start, m); err != nil {
+				return nil, err
+			}
+
+			return m, p.end()
+		case p.hasPrefix("{{"):
+			pattern, err := p.quotedPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			m.Pattern = pattern
+
+			return m, p.end()
+		default:
+			return nil, p.unexpected("declaration, .match or {{")
+		}
+	}
+}
+
+// end checks that there is only whitespace after message body.
+func (p *parser) end() error {
+	p.spaces()
+	if !p.eof() {
+		return p.unexpected("end of message")
+	}
+
+	return nil
+}
+
+// input parses .input {$name ...}, start is offset of the keyword.
+func (p *parser) input(start int) (*Declaration, error) {
+	p.spaces()
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Operand == nil || e.Operand.Variable == "" {
+		return nil, p.errorf(start, ".input requires a variable expression like {$name}")
+	}
+
+	name := e.Operand.Variable
+	if err := p.declare(start, name, e); err != nil {
+		return nil, err
+	}
+
+	p.use(e)
+
+	return &Declaration{Name: name, Value: e}, nil
+}
+
+// local parses .local $name = {...}, start is offset of the keyword.
+func (p *parser) local(start int) (*Declaration, error) {
+	if !p.spaces() {
+		return nil, p.unexpected("space")
+	}
+
+	name, err := p.variable()
+	if err != nil {
+		return nil, err
+	}
+
+	p.spaces()
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+
+	p.spaces()
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	// .local $x = {$x} is a duplicate declaration too
+	p.use(e)
+
+	if err := p.declare(start, name, e); err != nil {
+		return nil, err
+	}
+
+	return &Declaration{Local: true, Name: name, Value: e}, nil
+}
+
+// declare checks that variable was not declared or used before.
+func (p *parser) declare(start int, name string, e *Expression) error {
+	if p.declared[name] || p.used[name] {
+		return p.errorf(start, "duplicate declaration of $%s", name)
+	}
+
+	p.declared[name] = true
+	if e.Function != nil || (e.Operand != nil && p.annotated[e.Operand.Variable]) {
+		p.annotated[name] = true
+	}
+
+	return nil
+}
+
+// use marks variables of the expression as used, they could not be declared later.
+func (p *parser) use(e *Expression) {
+	if e.Operand != nil && e.Operand.Variable != "" {
+		p.used[e.Operand.Variable] = true
+	}
+
+	if e.Function == nil {
+		return
+	}
+
+	for _, o := range e.Function.Options {
+		if o.Value.Variable != "" {
+			p.used[o.Value.Variable] = true
+		}
+	}
+}
+
+// matcher parses selectors and variants of .match, start is offset of the keyword.
+func (p *parser) matcher(start int, m *Message) error {
+	for {
+		save := p.pos
+		if !p.spaces() || p.peek() != '$' {
+			p.pos = save
+
+			break
+		}
+
+		selStart := p.pos
+
+		name, err := p.variable()
+		if err != nil {
+			return err
+		}
+
+		if !p.annotated[name] {
+			return p.errorf(selStart, "selector $%s has no annotation, declare it like .input {$%s :string}", name, name)
+		}
+
+		m.Selectors = append(m.Selectors, name)
+	}
+
+	if len(m.Selectors) == 0 {
+		return p.unexpected("selector")
+	}
+
+	seen := map[string]bool{}
+	hasFallback := false
+	for {
+		p.spaces()
+		if p.eof() {
+			break
+		}
+
+		varStart := p.pos
+
+		v, err := p.variant()
+		if err != nil {
+			return err
+		}
+
+		if len(v.Keys) != len(m.Selectors) {
+			return p.errorf(varStart, "variant has %d keys, .match has %d selectors", len(v.Keys), len(m.Selectors))
+		}
+
+		keys := make([]string, 0, len(v.Keys))
+		fallback := true
+		for _, k := range v.Keys {
+			// * and |*| are different keys
+			if k.Star {
+				keys = append(keys, "*")
+			} else {
+				keys = append(keys, "|"+k.Value+"|")
+				fallback = false
+			}
+		}
+
+		id := strings.Join(keys, " ")
+		if seen[id] {
+			return p.errorf(varStart, "duplicate variant %s", id)
+		}
+
+		seen[id] = true
+		hasFallback = hasFallback || fallback
+		m.Variants = append(m.Variants, v)
+	}
+
+	if len(m.Variants) == 0 {
+		return p.unexpected("variant")
+	}
+
+	if !hasFallback {
+		return p.errorf(start, "no fallback variant, add %s{{...}}", strings.Repeat("* ", len(m.Selectors)))
+	}
+
+	return nil
+}
+
+// variant parses keys followed by a quoted pattern.
+func (p *parser) variant() (*Variant, error) {
+	v := &Variant{}
+	for {
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		v.Keys = append(v.Keys, k)
+
+		save := p.pos
+		if !p.spaces() || p.hasPrefix("{") || p.eof() {
+			p.pos = save
+
+			break
+		}
+	}
+
+	p.spaces()
+
+	pattern, err := p.quotedPattern()
+	if err != nil {
+		return nil, err
+	}
+
+	v.Pattern = pattern
+
+	return v, nil
+}
+
+func (p *parser) key() (Key, error) {
+	if p.peek() == '*' {
+		p.pos++
+
+		return Key{Star: true}, nil
+	}
+
+	lit, err := p.literal()
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{Value: lit}, nil
+}
+
+func (p *parser) quotedPattern() (Pattern, error) {
+	if err := p.expect("{{"); err != nil {
+		return nil, err
+	}
+
+	pattern, err := p.pattern()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("}}"); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+// pattern parses text and placeholders up to '}' or the end of message.
+func (p *parser) pattern() (Pattern, error) {
+	var (
+		parts Pattern
+		text  strings.Builder
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			parts = append(parts, &Part{Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case '\\':
+			c, err := p.escaped()
+			if err != nil {
+				return nil, err
+			}
+
+			text.WriteByte(c)
+		case '{':
+			flush()
+
+			part, err := p.placeholder()
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, part)
+		case '}':
+			flush()
+
+			return parts, nil
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+
+	return parts, nil
+}
+
+// escaped parses \\, \{, \| or \}.
+func (p *parser) escaped() (byte, error) {
+	p.pos++ // \
+	switch c := p.peek(); c {
+	case '\\', '{', '|', '}':
+		p.pos++
+
+		return c, nil
+	default:
+		return 0, p.unexpected(`\, {, | or } after \`)
+	}
+}
+
+// placeholder parses expression or markup.
+func (p *parser) placeholder() (*Part, error) {
+	save := p.pos
+	p.pos++ // {
+	p.spaces()
+
+	if c := p.peek(); c == '#' || c == '/' {
+		m, err := p.markup()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Part{Markup: m}, nil
+	}
+
+	p.pos = save
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Part{Expression: e}, nil
+}
+
+// expression parses {operand :function options @attributes}.
+func (p *parser) expression() (*Expression, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	p.spaces()
+
+	e := &Expression{}
+	switch c := p.peek(); {
+	case c == '$':
+		name, err := p.variable()
+		if err != nil {
+			return nil, err
+		}
+
+		e.Operand = &Operand{Variable: name}
+	case c == ':':
+	case c == '|' || isNameChar(p.peekRune()):
+		lit, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+
+		e.Operand = &Operand{Literal: lit}
+	default:
+		return nil, p.unexpected("variable, literal or function")
+	}
+
+	save := p.pos
+	if e.Operand == nil || (p.spaces() && p.peek() == ':') {
+		fn, err := p.function()
+		if err != nil {
+			return nil, err
+		}
+
+		e.Function = fn
+	} else {
+		p.pos = save
+	}
+
+	attrs, err := p.attributes()
+	if err != nil {
+		return nil, err
+	}
+
+	e.Attributes = attrs
+
+	p.spaces()
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// function parses :name with options.
+func (p *parser) function() (*Function, error) {
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := p.options()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Function{Name: name, Options: opts}, nil
+}
+
+// markup parses {#name}, {#name/} or {/name} after the opening brace.
+func (p *parser) markup() (*Markup, error) {
+	m := &Markup{Kind: MarkupOpen}
+	if p.peek() == '/' {
+		m.Kind = MarkupClose
+	}
+
+	p.pos++
+
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	m.Name = name
+
+	if m.Options, err = p.options(); err != nil {
+		return nil, err
+	}
+
+	if m.Attributes, err = p.attributes(); err != nil {
+		return nil, err
+	}
+
+	p.spaces()
+	if m.Kind == MarkupOpen && p.hasPrefix("/") {
+		m.Kind = MarkupStandalone
+		p.pos++
+	}
+
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// options parses space separated name=value options.
+func (p *parser) options() ([]*Option, error) {
+	var opts []*Option
+
+	seen := map[string]bool{}
+	for {
+		save := p.pos
+		if !p.spaces() || !isNameStart(p.peekRune()) {
+			p.pos = save
+
+			return opts, nil
+		}
+
+		start := p.pos
+
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[name] {
+			return nil, p.errorf(start, "duplicate option %s", name)
+		}
+
+		seen[name] = true
+
+		p.spaces()
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+
+		p.spaces()
+
+		o := &Option{Name: name}
+		if p.peek() == '$' {
+			o.Value.Variable, err = p.variable()
+		} else {
+			o.Value.Literal, err = p.literal()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, o)
+	}
+}
+
+// attributes parses space separated @name and @name=literal.
+func (p *parser) attributes() ([]*Attribute, error) {
+	var attrs []*Attribute
+	for {
+		save := p.pos
+		if !p.spaces() || p.peek() != '@' {
+			p.pos = save
+
+			return attrs, nil
+		}
+
+		p.pos++ // @
+
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+
+		a := &Attribute{Name: name}
+
+		save = p.pos
+		p.spaces()
+		if p.peek() == '=' {
+			p.pos++
+			p.spaces()
+
+			if a.Value, err = p.literal(); err != nil {
+				return nil, err
+			}
+		} else {
+			p.pos = save
+		}
+
+		attrs = append(attrs, a)
+	}
+}
+
+// variable parses $name and returns name.
+func (p *parser) variable() (string, error) {
+	if err := p.expect("$"); err != nil {
+		return "", err
+	}
+
+	return p.name()
+}
+
+// identifier parses name with optional namespace, like u:id.
+func (p *parser) identifier() (string, error) {
+	name, err := p.name()
+	if err != nil {
+		return "", err
+	}
+
+	if p.peek() != ':' {
+		return name, nil
+	}
+
+	p.pos++
+
+	local, err := p.name()
+	if err != nil {
+		return "", err
+	}
+
+	return name + ":" + local, nil
+}
+
+func (p *parser) name() (string, error) {
+	if p.eof() || !isNameStart(p.peekRune()) {
+		return "", p.unexpected("name")
+	}
+
+	start := p.pos
+	for !p.eof() {
+		r, n := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !isNameChar(r) {
+			break
+		}
+
+		p.pos += n
+	}
+
+	return p.src[start:p.pos], nil
+}
+
+// literal parses |quoted literal| or unquoted literal like 42 or one.
+func (p *parser) literal() (string, error) {
+	if p.peek() != '|' {
+		start := p.pos
+		for !p.eof() {
+			r, n := utf8.DecodeRuneInString(p.src[p.pos:])
+			if !isNameChar(r) {
+				break
+			}
+
+			p.pos += n
+		}
+
+		if start == p.pos {
+			return "", p.unexpected("literal")
+		}
+
+		return p.src[start:p.pos], nil
+	}
+
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case '\\':
+			c, err := p.escaped()
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteByte(c)
+		case '|':
+			p.pos++
+
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf(start, "unclosed literal, add '|' to close it")
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\u3000'
+}
+
+func isBidi(r rune) bool {
+	return r == '\u061c' || r == '\u200e' || r == '\u200f' || ('\u2066' <= r && r <= '\u2069')
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || unicode.IsDigit(r) || unicode.IsMark(r) ||
+		r == '-' || r == '.' || r == '\u00b7'
+}
+
+// position converts byte offset to position, columns are counted in runes.
+func position(src string, offset int) parse.Position {
+	pos := parse.Position{Offset: offset, Line: 1, Column: 1}
+	for _, r := range src[:offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+
+	return pos
+}
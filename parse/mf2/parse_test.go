@@ -0,0 +1,151 @@
+package mf2
+
+import (
+	"testing"
+
+	"github.com/fullpipe/icu-mf/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want *Message
+	}{
+		{
+			"simple",
+			"Hello, {$name}!",
+			&Message{Pattern: Pattern{
+				{Text: "Hello, "},
+				{Expression: &Expression{Operand: &Operand{Variable: "name"}}},
+				{Text: "!"},
+			}},
+		},
+		{
+			"escapes and literals",
+			` \{ {|a \| b|} {42 :number} `,
+			&Message{Pattern: Pattern{
+				{Text: " { "},
+				{Expression: &Expression{Operand: &Operand{Literal: "a | b"}}},
+				{Text: " "},
+				{Expression: &Expression{
+					Operand:  &Operand{Literal: "42"},
+					Function: &Function{Name: "number"},
+				}},
+				{Text: " "},
+			}},
+		},
+		{
+			"markup",
+			"{#link href=|/a| @x}go{/link}{#br/}",
+			&Message{Pattern: Pattern{
+				{Markup: &Markup{
+					Kind:       MarkupOpen,
+					Name:       "link",
+					Options:    []*Option{{Name: "href", Value: Operand{Literal: "/a"}}},
+					Attributes: []*Attribute{{Name: "x"}},
+				}},
+				{Text: "go"},
+				{Markup: &Markup{Kind: MarkupClose, Name: "link"}},
+				{Markup: &Markup{Kind: MarkupStandalone, Name: "br"}},
+			}},
+		},
+		{
+			"declarations",
+			".input {$n :number select=ordinal}\n.local $m = {$n :integer}\n{{{$m}}}",
+			&Message{
+				Declarations: []*Declaration{
+					{Name: "n", Value: &Expression{
+						Operand: &Operand{Variable: "n"},
+						Function: &Function{Name: "number", Options: []*Option{
+							{Name: "select", Value: Operand{Literal: "ordinal"}},
+						}},
+					}},
+					{Local: true, Name: "m", Value: &Expression{
+						Operand:  &Operand{Variable: "n"},
+						Function: &Function{Name: "integer"},
+					}},
+				},
+				Pattern: Pattern{{Expression: &Expression{Operand: &Operand{Variable: "m"}}}},
+			},
+		},
+		{
+			"match",
+			".input {$g :string} .input {$n :number}\n.match $g $n\nmale 1 {{one}}\n* * {{{$n} items}}",
+			&Message{
+				Declarations: []*Declaration{
+					{Name: "g", Value: &Expression{Operand: &Operand{Variable: "g"}, Function: &Function{Name: "string"}}},
+					{Name: "n", Value: &Expression{Operand: &Operand{Variable: "n"}, Function: &Function{Name: "number"}}},
+				},
+				Selectors: []string{"g", "n"},
+				Variants: []*Variant{
+					{Keys: []Key{{Value: "male"}, {Value: "1"}}, Pattern: Pattern{{Text: "one"}}},
+					{Keys: []Key{{Star: true}, {Star: true}}, Pattern: Pattern{
+						{Expression: &Expression{Operand: &Operand{Variable: "n"}}},
+						{Text: " items"},
+					}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"unclosed", "Hi {$name", `1:10: unexpected end of message, expected "}"`},
+		{"brace", "a } b", `1:3: unexpected '}', use \} for a literal brace`},
+		{"escape", `a \n`, `1:4: unexpected 'n', expected \, {, | or } after \`},
+		{"space before function", "{$x:number}", `1:4: unexpected ':', expected "}"`},
+		{"unclosed literal", "{|abc}", "1:2: unclosed literal, add '|' to close it"},
+		{"duplicate declaration", ".input {$x :number} .local $x = {1} {{}}", "1:21: duplicate declaration of $x"},
+		{"self reference", ".local $x = {$x} {{}}", "1:1: duplicate declaration of $x"},
+		{"duplicate option", "{$x :number style=a style=b}", "1:21: duplicate option style"},
+		{
+			"missing annotation",
+			".match $x * {{a}}",
+			"1:8: selector $x has no annotation, declare it like .input {$x :string}",
+		},
+		{
+			"keys mismatch",
+			".input {$x :string} .match $x a b {{a}} * {{b}}",
+			"1:31: variant has 2 keys, .match has 1 selectors",
+		},
+		{
+			"no fallback",
+			".input {$x :string} .match $x a {{a}}",
+			"1:21: no fallback variant, add * {{...}}",
+		},
+		{
+			"duplicate variant",
+			".input {$x :string} .match $x a {{a}} a {{b}} * {{c}}",
+			"1:39: duplicate variant |a|",
+		},
+		{"trailing text", "{{a}} b", `1:7: unexpected 'b', expected end of message`},
+		{"reserved statement", ".foo {{a}}", `1:1: unexpected '.', expected declaration, .match or {{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			require.Error(t, err)
+
+			var syntaxErr *parse.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Parse parses ICU message, errors are returned as *SyntaxError.
+func Parse(msg string) (*Message, error) {
+	tokens, err := lex(msg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: msg, tokens: tokens, failAt: -1}
+
+	m := p.message()
+	if p.peek().typ != tokEOF {
+		p.fail("EOF")
+
+		return nil, p.error()
+	}
+
+	return m, nil
+}
+
+// parser is a recursive-descent parser of the grammar in NewParser.
+// Like participle, it skips elided tokens unless a literal asks for them,
+// and backtracks when an alternative does not match.
+// Unlike NewParser, apostrophes and # follow ICU, see lex.
+type parser struct {
+	src    string
+	tokens []token
+	// pos is index of the next, possibly elided, token
+	pos int
+
+	// the deepest failure for error message
+	failAt   int
+	expected string
+
+	// recovering parser reports broken expressions to errs and skips them,
+	// see Validate
+	recovering bool
+	errs       []*SyntaxError
+	// offsets of parsed expressions, if recovering
+	exprAt map[*Expr]int
+	// unclosed brace is reported once, as the rest of message is skipped
+	unclosedReported bool
+}
+
+// next returns index of the next not elided token.
+func (p *parser) next() int {
+	return p.skipElided(p.pos)
+}
+
+// skipElided returns index of the first not elided token from i.
+func (p *parser) skipElided(i int) int {
+	for p.tokens[i].elided() {
+		i++
+	}
+
+	return i
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next()]
+}
+
+// accept consumes the next not elided token if it has the type.
+func (p *parser) accept(typ tokenType) (string, bool) {
+	i := p.next()
+	if p.tokens[i].typ != typ {
+		return "", false
+	}
+
+	p.pos = i + 1
+
+	return p.tokens[i].val, true
+}
+
+// literal consumes token with the value, elided tokens
+// before the next not elided one could be matched too.
+func (p *parser) literal(val string) bool {
+	for i := p.pos; ; i++ {
+		t := p.tokens[i]
+		if t.typ != tokEOF && t.typ != tokQuoted && t.val == val {
+			p.pos = i + 1
+
+			return true
+		}
+
+		if t.typ == tokEOF || !t.elided() {
+			return false
+		}
+	}
+}
+
+// fail remembers the deepest failure, it is reported if nothing matches.
+func (p *parser) fail(expected string) {
+	if i := p.next(); i >= p.failAt {
+		p.failAt = i
+		p.expected = expected
+	}
+}
+
+func (p *parser) error() error {
+	t := p.tokens[p.failAt]
+
+	val := t.val
+	if t.typ == tokEOF {
+		val = "<EOF>"
+	}
+
+	return &SyntaxError{
+		Pos: positionAt(p.src, t.offset),
+		Msg: fmt.Sprintf("unexpected token %q (expected %s)", val, p.expected),
+	}
+}
+
+// message parses fragments until the first one that does not match.
+func (p *parser) message() *Message {
+	m := &Message{}
+	for {
+		f := p.fragment()
+		if f == nil {
+			return m
+		}
+
+		m.Fragments = append(m.Fragments, f)
+	}
+}
+
+func (p *parser) fragment() *Fragment {
+	t := p.peek()
+	switch t.typ {
+	case tokEscaped, tokSubEscaped:
+		p.pos = p.next() + 1
+
+		return &Fragment{Escaped: t.val}
+	case tokString, tokSubString, tokQuote, tokSubQuote, tokQuoted:
+		p.pos = p.next() + 1
+
+		return &Fragment{Text: t.val}
+	case tokOctothorpe:
+		p.pos = p.next() + 1
+
+		return &Fragment{Octothorpe: true}
+	case tokExprStart:
+		// failures inside the expression are compared with each other,
+		// so a broken expression is reported at its own deepest failure
+		start, failAt, expected := p.pos, p.failAt, p.expected
+		p.failAt = -1
+
+		f := p.exprFragment()
+		if f == nil && p.recovering {
+			p.skipBroken(start)
+			p.failAt, p.expected = failAt, expected
+
+			return p.fragment()
+		}
+
+		if failAt > p.failAt {
+			p.failAt, p.expected = failAt, expected
+		}
+
+		if f != nil && f.Expr != nil && p.exprAt != nil {
+			p.exprAt[f.Expr] = p.tokens[p.skipElided(start)].offset
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+// exprFragment parses fragment in braces, trying every kind of expression.
+func (p *parser) exprFragment() *Fragment {
+	start := p.pos
+	if a := p.plainArg(); a != nil {
+		return &Fragment{PlainArg: a}
+	}
+
+	p.pos = start
+	if f := p.fn(); f != nil {
+		return &Fragment{Func: f}
+	}
+
+	p.pos = start
+	if e := p.expr(); e != nil {
+		return &Fragment{Expr: e}
+	}
+
+	p.pos = start
+	if e := p.multiExpr(); e != nil {
+		return &Fragment{Expr: e}
+	}
+
+	p.pos = start
+	if e := p.choiceExpr(); e != nil {
+		return &Fragment{Expr: e}
+	}
+
+	p.pos = start
+
+	return nil
+}
+
+// plainArg parses {name}.
+func (p *parser) plainArg() *PlainArg {
+	if !p.literal("{") {
+		return nil
+	}
+
+	name, ok := p.accept(tokIdent)
+	if !ok {
+		p.fail("<ident>")
+
+		return nil
+	}
+
+	if !p.literal("}") {
+		p.fail(`"}"`)
+
+		return nil
+	}
+
+	return &PlainArg{Name: name}
+}
+
+// fn parses {name, func} and {name, func, param}.
+func (p *parser) fn() *Func {
+	if !p.literal("{") {
+		return nil
+	}
+
+	var (
+		f  Func
+		ok bool
+	)
+
+	if f.ArgName, ok = p.accept(tokIdent); !ok {
+		p.fail("<ident>")
+
+		return nil
+	}
+
+	if !p.literal(",") {
+		p.fail(`","`)
+
+		return nil
+	}
+
+	if f.Func, ok = p.accept(tokIdent); !ok {
+		p.fail("<ident>")
+
+		return nil
+	}
+
+	start := p.pos
+	if !p.literal(",") {
+		p.pos = start
+	} else if f.Param, ok = p.accept(tokIdent); !ok {
+		p.pos = start
+	}
+
+	if !p.literal("}") {
+		p.fail(`"}"`)
+
+		return nil
+	}
+
+	return &f
+}
+
+// expr parses {name, func, offset:N cases...}, function and offset are optional.
+func (p *parser) expr() *Expr {
+	if !p.literal("{") {
+		return nil
+	}
+
+	var (
+		e  Expr
+		ok bool
+	)
+
+	if e.Name, ok = p.accept(tokIdent); !ok {
+		p.fail("<ident>")
+
+		return nil
+	}
+
+	start := p.pos
+	if !p.literal(",") {
+		p.pos = start
+	} else if e.Func, ok = p.accept(tokIdent); !ok {
+		p.pos = start
+	}
+
+	if e.Offset, ok = p.offset(); !ok {
+		return nil
+	}
+
+	return p.exprCases(&e, 1)
+}
+
+// multiExpr parses {name, name, func func, offset:N cases...}
+// with a function per argument and a key per argument in every case.
+func (p *parser) multiExpr() *Expr {
+	if !p.literal("{") {
+		return nil
+	}
+
+	// failures in names are not reported, it is not a multi-selector expression
+	var names []string
+	for {
+		name, ok := p.accept(tokIdent)
+		if !ok || !p.literal(",") {
+			return nil
+		}
+
+		names = append(names, name)
+		if len(names) > 1 && p.identsAhead() {
+			break
+		}
+	}
+
+	e := Expr{Name: names[0]}
+	for i, name := range names {
+		fn, ok := p.spacedIdent()
+		if !ok {
+			p.fail("<ident>")
+
+			return nil
+		}
+
+		if i == 0 {
+			e.Func = fn
+		} else {
+			e.Selectors = append(e.Selectors, &Selector{Name: name, Func: fn})
+		}
+	}
+
+	var ok bool
+	if e.Offset, ok = p.offset(); !ok {
+		return nil
+	}
+
+	return p.exprCases(&e, len(names))
+}
+
+// identsAhead reports if the next ident is followed by another one
+// separated with spaces only, like functions of multi-selector expression.
+func (p *parser) identsAhead() bool {
+	i := p.next()
+	if p.tokens[i].typ != tokIdent {
+		return false
+	}
+
+	for i++; p.tokens[i].typ == tokWhitespace; i++ {
+	}
+
+	return p.tokens[i].typ == tokIdent
+}
+
+// spacedIdent consumes ident that is separated with spaces only.
+func (p *parser) spacedIdent() (string, bool) {
+	i := p.pos
+	for p.tokens[i].typ == tokWhitespace {
+		i++
+	}
+
+	if p.tokens[i].typ != tokIdent {
+		return "", false
+	}
+
+	p.pos = i + 1
+
+	return p.tokens[i].val, true
+}
+
+// offset parses optional ", offset:N", it is not ok for invalid N.
+func (p *parser) offset() (int, bool) {
+	start := p.pos
+	if !p.literal(",") || !p.literal("offset") || !p.literal(":") {
+		p.pos = start
+
+		return 0, true
+	}
+
+	i := p.next()
+	val, ok := p.accept(tokInt)
+	if !ok {
+		p.pos = start
+
+		return 0, true
+	}
+
+	n, ok := parseInt(val)
+	if !ok {
+		p.pos = i
+		p.fail("<int>")
+	}
+
+	return n, ok
+}
+
+// choiceExpr parses legacy {name, choice, 0#none|1#one|1<many}.
+// Case names are limits with their separators, like 0# or 1<.
+func (p *parser) choiceExpr() *Expr {
+	if !p.literal("{") {
+		return nil
+	}
+
+	// failures in the header are reported by expr
+	name, ok := p.accept(tokIdent)
+	if !ok || !p.literal(",") || !p.literal("choice") {
+		return nil
+	}
+
+	e := &Expr{Name: name, Func: "choice"}
+	for {
+		limit, ok := p.accept(tokChoiceLimit)
+		if !ok {
+			p.fail("<limit>")
+
+			return nil
+		}
+
+		sep, ok := p.accept(tokChoiceSep)
+		if !ok {
+			p.fail(`"#", "<" or "≤"`)
+
+			return nil
+		}
+
+		e.Cases = append(e.Cases, &Case{Name: limit + sep, Message: p.message()})
+		if _, ok := p.accept(tokChoicePipe); !ok {
+			break
+		}
+	}
+
+	if !p.literal("}") {
+		p.fail(`"}"`)
+
+		return nil
+	}
+
+	return e
+}
+
+// exprCases parses cases with keys keys each and the closing brace.
+func (p *parser) exprCases(e *Expr, keys int) *Expr {
+	for {
+		start := p.pos
+		c := p.exprCase(keys)
+		if c == nil {
+			p.pos = start
+
+			break
+		}
+
+		e.Cases = append(e.Cases, c)
+	}
+
+	if !p.literal("}") {
+		p.fail(`"}"`)
+
+		return nil
+	}
+
+	return e
+}
+
+// exprCase parses name {message}, or several names for multi-selector expression.
+func (p *parser) exprCase(keys int) *Case {
+	c := &Case{}
+	for i := range keys {
+		name, ok := p.accept(tokIdent)
+		if !ok {
+			if name, ok = p.accept(tokCase); !ok {
+				if i > 0 {
+					p.fail("<ident>")
+				}
+
+				return nil
+			}
+		}
+
+		if i == 0 {
+			c.Name = name
+		} else {
+			c.Keys = append(c.Keys, name)
+		}
+	}
+
+	if !p.literal("{") {
+		p.fail(`"{" Message "}"`)
+
+		return nil
+	}
+
+	msg := p.message()
+
+	if !p.literal("}") {
+		p.fail(`"}"`)
+
+		return nil
+	}
+
+	c.Message = msg
+
+	return c
+}
+
+// parseInt parses offset like participle does, with base prefixes.
+func parseInt(s string) (int, bool) {
+	n, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(n), true
+}
@@ -0,0 +1,361 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var corpus = []string{
+	"",
+	"Hello, world!",
+	"Hello {name}!",
+	"foo '{ '' ' foo",
+	"{age, number, integer}",
+	"{d, date}",
+	"{d, date, short, }",
+	"{n,,number}",
+	"{ name }",
+	"{n , plural , one {x}}",
+	"{gender, select, male {He} female {She} other {They}} liked this.",
+	"{count, plural, =0 {no results} one {# result} other {# results}}",
+	"{count, plural, offset:1 =0 {nobody} one {you} other {you and # others}}",
+	"{count, plural, offset:010 other {#}}",
+	"{pos, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}",
+	"{g, select, a {{n, plural, one {'{' '}' '#' ''} other {{x}}}} other {}}",
+	"{n, plural one {x} other {y}}",
+	"{n, plural, one {x}, other {y}}",
+	"{A A0{0}}",
+	"{n number}",
+	"{n, number integer}",
+	"{n, plural, offset 1 one {x}}",
+	"{n, plural, offset:09 other {x}}",
+	"{n, plural, one {^}}",
+	"{name",
+	"{}",
+	"{1}",
+	"foo\n{bar!",
+	"}{",
+	"{a, b, select plural, x y {z} other other {#}}",
+	"{n, choice, 0#no files|1#one file|1<{n, number} files}",
+}
+
+// golden are parsed messages of the corpus, or their errors.
+// Unlike the reference grammar, they cover ICU quoting, #, choice
+// and multi-selector expressions.
+var golden = map[string]struct {
+	want *Message
+	err  string
+}{
+	"":                       {want: msg()},
+	"Hello, world!":          {want: msg(text("Hello, world!"))},
+	"Hello {name}!":          {want: msg(text("Hello "), arg("name"), text("!"))},
+	"foo '{ '' ' foo":        {want: msg(text("foo "), text("{ ' "), text(" foo"))},
+	"{age, number, integer}": {want: msg(fn("age", "number", "integer"))},
+	"{d, date}":              {want: msg(fn("d", "date", ""))},
+	"{d, date, short, }":     {want: msg(fn("d", "date", "short"))},
+	"{n,,number}":            {want: msg(fn("n", "number", ""))},
+	"{ name }":               {want: msg(arg("name"))},
+	"{n , plural , one {x}}": {want: msg(expr("n", "plural", 0, caseOf("one", text("x"))))},
+	"{gender, select, male {He} female {She} other {They}} liked this.": {want: msg(
+		expr("gender", "select", 0, caseOf("male", text("He")), caseOf("female", text("She")), caseOf("other", text("They"))),
+		text(" liked this."),
+	)},
+	"{count, plural, =0 {no results} one {# result} other {# results}}": {want: msg(expr("count", "plural", 0,
+		caseOf("=0", text("no results")),
+		caseOf("one", hash(), text(" result")),
+		caseOf("other", hash(), text(" results")),
+	))},
+	"{count, plural, offset:1 =0 {nobody} one {you} other {you and # others}}": {want: msg(expr("count", "plural", 1,
+		caseOf("=0", text("nobody")),
+		caseOf("one", text("you")),
+		caseOf("other", text("you and "), hash(), text(" others")),
+	))},
+	"{count, plural, offset:010 other {#}}": {want: msg(expr("count", "plural", 8, caseOf("other", hash())))},
+	"{pos, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}": {want: msg(expr("pos", "selectordinal", 0,
+		caseOf("one", hash(), text("st")),
+		caseOf("two", hash(), text("nd")),
+		caseOf("few", hash(), text("rd")),
+		caseOf("other", hash(), text("th")),
+	))},
+	"{g, select, a {{n, plural, one {'{' '}' '#' ''} other {{x}}}} other {}}": {want: msg(expr("g", "select", 0,
+		caseOf("a", &Fragment{Expr: expr("n", "plural", 0,
+			caseOf("one", text("{"), text(" "), text("}"), text(" "), text("#"), text(" "), &Fragment{Escaped: "''"}),
+			caseOf("other", arg("x")),
+		).Expr}),
+		caseOf("other"),
+	))},
+	"{n, plural one {x} other {y}}":    {want: msg(expr("n", "plural", 0, caseOf("one", text("x")), caseOf("other", text("y"))))},
+	"{n, plural, one {x}, other {y}}":  {want: msg(expr("n", "plural", 0, caseOf("one", text("x")), caseOf("other", text("y"))))},
+	"{A A0{0}}":                        {want: msg(expr("A", "", 0, caseOf("A0", text("0"))))},
+	"{n number}":                       {err: `1:10: unexpected token "}" (expected "{" Message "}")`},
+	"{n, number integer}":              {err: `1:19: unexpected token "}" (expected "{" Message "}")`},
+	"{n, plural, offset 1 one {x}}":    {err: `1:20: unexpected token "1" (expected "{" Message "}")`},
+	"{n, plural, offset:09 other {x}}": {err: `1:20: unexpected token "09" (expected <int>)`},
+	"{n, plural, one {^}}":             {want: msg(expr("n", "plural", 0, caseOf("one", text("^"))))},
+	"{name":                            {err: `1:6: unexpected token "<EOF>" (expected "}")`},
+	"{}":                               {err: `1:2: unexpected token "}" (expected <ident>)`},
+	"{1}":                              {err: `1:2: unexpected token "1" (expected <ident>)`},
+	"foo\n{bar!":                       {err: `2:5: lexer: invalid input text "!"`},
+	"}{":                               {err: `1:3: unexpected token "<EOF>" (expected <ident>)`},
+	"{a, b, select plural, x y {z} other other {#}}": {want: msg(&Fragment{Expr: &Expr{
+		Name:      "a",
+		Func:      "select",
+		Selectors: []*Selector{{Name: "b", Func: "plural"}},
+		Cases: []*Case{
+			{Name: "x", Keys: []string{"y"}, Message: msg(text("z"))},
+			{Name: "other", Keys: []string{"other"}, Message: msg(hash())},
+		},
+	}})},
+	"{n, choice, 0#no files|1#one file|1<{n, number} files}": {want: msg(expr("n", "choice", 0,
+		caseOf("0#", text("no files")),
+		caseOf("1#", text("one file")),
+		caseOf("1<", fn("n", "number", ""), text(" files")),
+	))},
+}
+
+func msg(fragments ...*Fragment) *Message { return &Message{Fragments: fragments} }
+func text(s string) *Fragment             { return &Fragment{Text: s} }
+func hash() *Fragment                     { return &Fragment{Octothorpe: true} }
+func arg(name string) *Fragment           { return &Fragment{PlainArg: &PlainArg{Name: name}} }
+
+func fn(name, fn, param string) *Fragment {
+	return &Fragment{Func: &Func{ArgName: name, Func: fn, Param: param}}
+}
+
+func expr(name, fn string, offset int, cases ...*Case) *Fragment {
+	return &Fragment{Expr: &Expr{Name: name, Func: fn, Offset: offset, Cases: cases}}
+}
+
+func caseOf(name string, fragments ...*Fragment) *Case {
+	return &Case{Name: name, Message: msg(fragments...)}
+}
+
+func TestParse(t *testing.T) {
+	reference := NewParser()
+
+	for _, in := range corpus {
+		t.Run(in, func(t *testing.T) {
+			tt, ok := golden[in]
+			require.True(t, ok, "no golden result")
+
+			got, err := Parse(in)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			if referenceApplies(in) {
+				assertSameAsReference(t, reference, in)
+			}
+		})
+	}
+}
+
+func TestParse_AST(t *testing.T) {
+	msg, err := Parse("Hi {name}, {n, plural, offset:1 =0 {none} other {# {n, number}}}")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Message{Fragments: []*Fragment{
+		{Text: "Hi "},
+		{PlainArg: &PlainArg{Name: "name"}},
+		{Text: ", "},
+		{Expr: &Expr{Name: "n", Func: "plural", Offset: 1, Cases: []*Case{
+			{Name: "=0", Message: &Message{Fragments: []*Fragment{{Text: "none"}}}},
+			{Name: "other", Message: &Message{Fragments: []*Fragment{
+				{Octothorpe: true},
+				{Text: " "},
+				{Func: &Func{ArgName: "n", Func: "number"}},
+			}}},
+		}}},
+	}}, msg)
+}
+
+func TestParse_MultiSelector(t *testing.T) {
+	msg, err := Parse("{gender, count, select plural, offset:1 female one {# her} other =0 {none} other other {#}}")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Message{Fragments: []*Fragment{
+		{Expr: &Expr{
+			Name:      "gender",
+			Func:      "select",
+			Offset:    1,
+			Selectors: []*Selector{{Name: "count", Func: "plural"}},
+			Cases: []*Case{
+				{Name: "female", Keys: []string{"one"}, Message: &Message{Fragments: []*Fragment{
+					{Octothorpe: true},
+					{Text: " her"},
+				}}},
+				{Name: "other", Keys: []string{"=0"}, Message: &Message{Fragments: []*Fragment{{Text: "none"}}}},
+				{Name: "other", Keys: []string{"other"}, Message: &Message{Fragments: []*Fragment{{Octothorpe: true}}}},
+			},
+		}},
+	}}, msg)
+
+	for _, in := range []string{
+		"{a, b, c, select plural, x y z {}}",
+		"{a, b, select plural, x {y}}",
+		"{a, b, select plural, x y}",
+	} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParse_Choice(t *testing.T) {
+	msg, err := Parse("{n, choice, -∞<neg| 0#none|1 ≤ one {n}|1<'|'many}")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Message{Fragments: []*Fragment{
+		{Expr: &Expr{Name: "n", Func: "choice", Cases: []*Case{
+			{Name: "-∞<", Message: &Message{Fragments: []*Fragment{{Text: "neg"}}}},
+			{Name: "0#", Message: &Message{Fragments: []*Fragment{{Text: "none"}}}},
+			{Name: "1≤", Message: &Message{Fragments: []*Fragment{
+				{Text: " one "},
+				{PlainArg: &PlainArg{Name: "n"}},
+			}}},
+			{Name: "1<", Message: &Message{Fragments: []*Fragment{{Text: "|"}, {Text: "many"}}}},
+		}}},
+	}}, msg)
+
+	for _, in := range []string{
+		"{n, choice, x}",
+		"{n, choice, 0#a",
+		"{n, choice, 0 a}",
+		"{n, choice, 0#a|}",
+	} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+// FuzzParse checks Parse against the participle grammar, messages it does not
+// apply to must print and parse back to the same tree.
+func FuzzParse(f *testing.F) {
+	for _, in := range corpus {
+		f.Add(in)
+	}
+
+	reference := NewParser()
+	f.Fuzz(func(t *testing.T, in string) {
+		if referenceApplies(in) {
+			assertSameAsReference(t, reference, in)
+
+			return
+		}
+
+		got, err := Parse(in)
+		if err != nil {
+			return
+		}
+
+		again, err := Parse(got.String())
+		require.NoError(t, err, got.String())
+		assert.Equal(t, normalize(got), normalize(again), got.String())
+	})
+}
+
+// referenceApplies reports if the participle grammar parses in like Parse:
+// quoting and # follow ICU and differ from it, it also rejects ^
+// in case messages and has no choice.
+func referenceApplies(in string) bool {
+	return !strings.ContainsAny(in, "'#^") && !strings.Contains(in, "choice")
+}
+
+// assertSameAsReference checks Parse against the participle grammar,
+// it has no multi-selector expressions, they must print and parse back
+// to the same tree instead.
+func assertSameAsReference(t *testing.T, reference *participle.Parser[Message], in string) {
+	t.Helper()
+
+	want, wantErr := reference.ParseString("", in)
+	got, err := Parse(in)
+
+	if wantErr != nil && err == nil && hasSelectors(got) {
+		again, err := Parse(got.String())
+		require.NoError(t, err, got.String())
+		assert.Equal(t, normalize(got), normalize(again), got.String())
+
+		return
+	}
+
+	if wantErr != nil {
+		require.Error(t, err, "reference error: %v", wantErr)
+
+		return
+	}
+
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func hasSelectors(m *Message) bool {
+	found := false
+	Inspect(m, func(n Node) bool {
+		if e, ok := n.(*Expr); ok && len(e.Selectors) > 0 {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		for _, in := range corpus {
+			_, _ = Parse(in)
+		}
+	}
+}
+
+func BenchmarkParse_Participle(b *testing.B) {
+	parser := NewParser()
+	b.ReportAllocs()
+
+	for range b.N {
+		for _, in := range corpus {
+			_, _ = parser.ParseString("", in)
+		}
+	}
+}
+
+// normalize merges adjacent text fragments, as printing
+// could quote text differently, and returns m.
+func normalize(m *Message) *Message {
+	Inspect(m, func(n Node) bool {
+		msg, ok := n.(*Message)
+		if !ok {
+			return true
+		}
+
+		var fragments []*Fragment
+		for _, f := range msg.Fragments {
+			if f.Escaped != "" {
+				f = &Fragment{Text: "'"}
+			}
+
+			if last := len(fragments) - 1; last >= 0 && f.Text != "" && fragments[last].Text != "" {
+				fragments[last] = &Fragment{Text: fragments[last].Text + f.Text}
+
+				continue
+			}
+
+			fragments = append(fragments, f)
+		}
+
+		msg.Fragments = fragments
+
+		return true
+	})
+
+	return m
+}
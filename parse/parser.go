@@ -20,11 +20,23 @@ type Expr struct {
 	Func   string  `("," @Ident)?`
 	Offset int     `("," "offset" ":" @Int)?`
 	Cases  []*Case `(@@*)? "}"`
+	// Selectors are the rest of arguments of multi-selector expression,
+	// like count in {gender, count, select plural, ...}.
+	// Name and Func are the first argument. NewParser does not support them.
+	Selectors []*Selector
+}
+
+// Selector is an argument with its function.
+type Selector struct {
+	Name string
+	Func string
 }
 
 type Case struct {
 	Name    string   `(@Ident | @Case)`
 	Message *Message `"{" @@ "}"`
+	// Keys are the rest of keys of multi-selector case, like one in {female one {...}}.
+	Keys []string
 }
 
 type Message struct {
@@ -40,6 +52,10 @@ type Fragment struct {
 	Octothorpe bool      `| @"#"`
 }
 
+// NewParser builds participle parser of the message grammar.
+//
+// Deprecated: use Parse, it is faster and produces the same AST.
+// NewParser is kept as the reference grammar.
 func NewParser() *participle.Parser[Message] {
 	def := lexer.MustStateful(lexer.Rules{
 		"Root": {
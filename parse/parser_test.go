@@ -24,3 +24,17 @@ func TestParser_Escaping(t *testing.T) {
 	assert.Equal(t, &Fragment{Text: "'"}, msg.Fragments[5])
 	assert.Equal(t, &Fragment{Text: " foo"}, msg.Fragments[6])
 }
+
+func TestParse_SyntaxError(t *testing.T) {
+	_, err := Parse("foo\n{bar!")
+
+	var syntaxErr *SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, 2, syntaxErr.Pos.Line)
+	assert.Equal(t, 5, syntaxErr.Pos.Column)
+	assert.Equal(t, "2:5: "+syntaxErr.Msg, err.Error())
+
+	msg, err := Parse("foo {bar}")
+	require.NoError(t, err)
+	assert.Len(t, msg.Fragments, 2)
+}
@@ -0,0 +1,185 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// String formats the message back to ICU syntax.
+// Parse of the result gives an equivalent message, special characters
+// of text are quoted where they need to be.
+func (m *Message) String() string {
+	var b strings.Builder
+	printMessage(&b, m, "{}")
+
+	return b.String()
+}
+
+// printMessage prints fragments, special are characters that must be quoted:
+// braces, and # in plural or | in choice case messages.
+func printMessage(b *strings.Builder, m *Message, special string) {
+	if m == nil {
+		return
+	}
+
+	// adjacent text is quoted at once
+	var text strings.Builder
+	for _, f := range m.Fragments {
+		switch {
+		case f.Escaped != "":
+			text.WriteString(f.Escaped[1:])
+
+			continue
+		case f.PlainArg == nil && f.Func == nil && f.Expr == nil && !f.Octothorpe:
+			text.WriteString(f.Text)
+
+			continue
+		}
+
+		printText(b, text.String(), special)
+		text.Reset()
+
+		switch {
+		case f.PlainArg != nil:
+			b.WriteString("{" + f.PlainArg.Name + "}")
+		case f.Func != nil:
+			b.WriteString("{" + f.Func.ArgName + ", " + f.Func.Func)
+			if f.Func.Param != "" {
+				b.WriteString(", " + f.Func.Param)
+			}
+
+			b.WriteString("}")
+		case f.Expr != nil:
+			printExpr(b, f.Expr)
+		case f.Octothorpe:
+			b.WriteString("#")
+		}
+	}
+
+	printText(b, text.String(), special)
+}
+
+func printExpr(b *strings.Builder, e *Expr) {
+	b.WriteString(exprOpen(e))
+
+	if e.Func == "choice" {
+		for i, c := range e.Cases {
+			if i == 0 {
+				b.WriteString(", ")
+			} else {
+				b.WriteString("|")
+			}
+
+			b.WriteString(c.Name)
+			printMessage(b, c.Message, "{}|")
+		}
+
+		b.WriteString("}")
+
+		return
+	}
+
+	special := caseSpecial(e)
+	for _, c := range e.Cases {
+		b.WriteString(caseOpen(c))
+		printMessage(b, c.Message, special)
+		b.WriteString("}")
+	}
+
+	b.WriteString("}")
+}
+
+// exprOpen prints expression up to its cases, like {n, plural, offset:1
+func exprOpen(e *Expr) string {
+	var b strings.Builder
+
+	b.WriteString("{" + e.Name)
+	for _, s := range e.Selectors {
+		b.WriteString(", " + s.Name)
+	}
+
+	if e.Func != "" {
+		b.WriteString(", " + e.Func)
+	}
+
+	for _, s := range e.Selectors {
+		b.WriteString(" " + s.Func)
+	}
+
+	switch {
+	case e.Func == "choice":
+	case e.Offset != 0:
+		b.WriteString(", offset:" + strconv.Itoa(e.Offset))
+	case len(e.Cases) > 0 && e.Func != "":
+		b.WriteString(",")
+	}
+
+	return b.String()
+}
+
+// caseOpen prints case keys with the opening brace, like " one {".
+func caseOpen(c *Case) string {
+	open := " " + c.Name
+	for _, k := range c.Keys {
+		open += " " + k
+	}
+
+	return open + " {"
+}
+
+// caseSpecial returns characters to quote in case messages of the expression.
+func caseSpecial(e *Expr) string {
+	if hasPlural(exprHeader(e)) {
+		return "{}#"
+	}
+
+	return "{}"
+}
+
+// exprHeader lists names and functions of the expression as the lexer sees them.
+func exprHeader(e *Expr) []string {
+	header := []string{e.Name}
+	for _, s := range e.Selectors {
+		header = append(header, s.Name)
+	}
+
+	header = append(header, e.Func)
+	for _, s := range e.Selectors {
+		header = append(header, s.Func)
+	}
+
+	return header
+}
+
+// printText quotes text from its first to its last special character.
+// Apostrophes are doubled in quotes, and out of quotes before another
+// apostrophe or at the end, where the next part could start quoting.
+func printText(b *strings.Builder, text, special string) {
+	escape := strings.NewReplacer("'", "''")
+
+	first, last := strings.IndexAny(text, special), strings.LastIndexAny(text, special)
+	if first < 0 {
+		printApostrophes(b, text)
+
+		return
+	}
+
+	// apostrophes right after the quote would continue it, they are quoted too
+	for last+1 < len(text) && text[last+1] == '\'' {
+		last++
+	}
+
+	printApostrophes(b, text[:first])
+	b.WriteString("'" + escape.Replace(text[first:last+1]) + "'")
+	printApostrophes(b, text[last+1:])
+}
+
+// printApostrophes writes unquoted text, apostrophes before regular characters are literal as is.
+func printApostrophes(b *strings.Builder, text string) {
+	for i := range len(text) {
+		b.WriteByte(text[i])
+		if text[i] == '\'' && (i+1 == len(text) || text[i+1] == '\'') {
+			b.WriteByte('\'')
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_String(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hello, {name}!", "Hello, {name}!"},
+		{"{ n ,number,integer }", "{n, number, integer}"},
+		{"It''s '{'name'}' {x}", "It's '{name}' {x}"},
+		{"a'''b end'", "a'''b end''"},
+		{"'{''''0'0", "'{'''''00"},
+		{"a } b", "a '}' b"},
+		{"{n,plural,offset:1 =0{none} one{'#' #} other{'{x}'''}}", "{n, plural, offset:1 =0 {none} one {'#' #} other {'{x}'''}}"},
+		{"{g,select,a{#} other{{n}}}", "{g, select, a {#} other {{n}}}"},
+		{"{g, n, select plural, a one {#} other other {x}}", "{g, n, select plural, a one {#} other other {x}}"},
+		{"{n,choice,0#none |1<'|'# {n}}", "{n, choice, 0#none |1<'|'# {n}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			msg, err := Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, msg.String())
+		})
+	}
+}
+
+// FuzzMessage_String checks that printed messages parse back to the same message.
+func FuzzMessage_String(f *testing.F) {
+	for _, in := range corpus {
+		f.Add(in)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		msg, err := Parse(in)
+		if err != nil {
+			t.Skip()
+		}
+
+		printed := msg.String()
+		again, err := Parse(printed)
+		require.NoError(t, err, printed)
+		assert.Equal(t, printed, again.String())
+	})
+}
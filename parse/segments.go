@@ -0,0 +1,215 @@
+package parse
+
+import "strings"
+
+// Segment is a part of a message for translation tools: either translatable
+// Text, or ICU Code around it, like {name}, # or {n, plural, one {.
+type Segment struct {
+	Text string
+	Code string
+}
+
+// Segments splits the message to translatable text and code,
+// so tools could protect the code from translators:
+//
+//	You have {n, plural, one {# file} other {# files}}
+//
+// is "You have ", code "{n, plural, one {#", " file", code "} other {#",
+// " files" and code "}}". Adjacent parts of the same kind are merged.
+// Choice cases are split the same way, limits like 0# or |1< are code.
+func Segments(m *Message) []Segment {
+	var s segmenter
+	s.message(m)
+
+	return s.segs
+}
+
+type segmenter struct {
+	segs []Segment
+}
+
+func (s *segmenter) text(text string) {
+	if n := len(s.segs); n > 0 && s.segs[n-1].Code == "" {
+		s.segs[n-1].Text += text
+
+		return
+	}
+
+	s.segs = append(s.segs, Segment{Text: text})
+}
+
+func (s *segmenter) code(code string) {
+	if n := len(s.segs); n > 0 && s.segs[n-1].Code != "" {
+		s.segs[n-1].Code += code
+
+		return
+	}
+
+	s.segs = append(s.segs, Segment{Code: code})
+}
+
+func (s *segmenter) message(m *Message) {
+	if m == nil {
+		return
+	}
+
+	for _, f := range m.Fragments {
+		switch {
+		case f.Escaped != "":
+			s.text(f.Escaped[1:])
+		case f.PlainArg != nil, f.Func != nil, f.Octothorpe:
+			s.code((&Message{Fragments: []*Fragment{f}}).String())
+		case f.Expr != nil && f.Expr.Func == "choice":
+			s.code(exprOpen(f.Expr))
+			for i, c := range f.Expr.Cases {
+				if i == 0 {
+					s.code(", " + c.Name)
+				} else {
+					s.code("|" + c.Name)
+				}
+
+				s.message(c.Message)
+			}
+
+			s.code("}")
+		case f.Expr != nil:
+			s.code(exprOpen(f.Expr))
+			for _, c := range f.Expr.Cases {
+				s.code(caseOpen(c))
+				s.message(c.Message)
+				s.code("}")
+			}
+
+			s.code("}")
+		default:
+			s.text(f.Text)
+		}
+	}
+}
+
+// JoinSegments joins segments back to a message, text is quoted
+// where it is needed at its place in the message. Segments could be
+// translated, reordered or dropped, the result must be checked with Parse.
+func JoinSegments(segs []Segment) string {
+	var (
+		b strings.Builder
+		j joiner
+	)
+
+	for _, s := range segs {
+		if s.Code == "" {
+			printText(&b, s.Text, j.special())
+
+			continue
+		}
+
+		b.WriteString(s.Code)
+		j.scan(s.Code)
+	}
+
+	return b.String()
+}
+
+// joiner follows nesting of code to know special characters of text after it.
+type joiner struct {
+	frames []joinFrame
+}
+
+// joinFrame is an expression, or a case message of the expression below it.
+type joinFrame struct {
+	expr   bool
+	header string
+	cases  bool
+	// choice expression, or its case message ended by | or }
+	choice bool
+	// special characters of case messages
+	special string
+}
+
+func (j *joiner) top() *joinFrame {
+	if len(j.frames) == 0 {
+		return nil
+	}
+
+	return &j.frames[len(j.frames)-1]
+}
+
+func (j *joiner) special() string {
+	if top := j.top(); top != nil && !top.expr {
+		return top.special
+	}
+
+	return "{}"
+}
+
+func (j *joiner) scan(code string) {
+	for i := range len(code) {
+		c := code[i]
+
+		top := j.top()
+		if top == nil || !top.expr {
+			switch {
+			case top != nil && top.choice && c == '|':
+				j.frames = j.frames[:len(j.frames)-1]
+			case top != nil && top.choice && c == '}':
+				// the last choice message ends with the expression
+				j.frames = j.frames[:len(j.frames)-2]
+			case c == '{':
+				j.frames = append(j.frames, joinFrame{expr: true})
+			case c == '}' && top != nil:
+				j.frames = j.frames[:len(j.frames)-1]
+			}
+
+			continue
+		}
+
+		switch {
+		case top.choice && (c == '#' || c == '<' || strings.HasPrefix(code[i:], "≤")):
+			j.frames = append(j.frames, joinFrame{choice: true, special: "{}|"})
+		case top.choice && c == '}':
+			j.frames = j.frames[:len(j.frames)-1]
+		case top.choice:
+			// limits
+		case c == '{':
+			if !top.cases {
+				top.cases = true
+				top.special = "{}"
+				if hasPlural(headerIdents(top.header)) {
+					top.special = "{}#"
+				}
+			}
+
+			j.frames = append(j.frames, joinFrame{special: top.special})
+		case c == '}':
+			j.frames = j.frames[:len(j.frames)-1]
+		case !top.cases:
+			// {n, choice, ...} like the lexer
+			if c == ',' {
+				ids := headerIdents(top.header)
+				top.choice = len(ids) == 2 && ids[1] == "choice"
+			}
+
+			top.header += string(c)
+		}
+	}
+}
+
+// headerIdents returns identifiers of expression header like the lexer sees them,
+// numbers and =N cases are not identifiers.
+func headerIdents(header string) []string {
+	var ids []string
+	for i := 0; i < len(header); {
+		switch c := header[i]; {
+		case isDigit(c):
+			i += span(header[i:], isDigit)
+		case isWord(c):
+			n := span(header[i:], isWord)
+			ids = append(ids, header[i:i+n])
+			i += n
+		default:
+			i++
+		}
+	}
+
+	return ids
+}
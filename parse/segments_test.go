@@ -0,0 +1,100 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Segment
+	}{
+		{"Hello, {name}!", []Segment{{Text: "Hello, "}, {Code: "{name}"}, {Text: "!"}}},
+		{"It''s '{'{n, number}", []Segment{{Text: "It's {"}, {Code: "{n, number}"}}},
+		{
+			"You have {n, plural, one {# file} other {# files}}",
+			[]Segment{
+				{Text: "You have "},
+				{Code: "{n, plural, one {#"},
+				{Text: " file"},
+				{Code: "} other {#"},
+				{Text: " files"},
+				{Code: "}}"},
+			},
+		},
+		{"{g, select, a {{x}} other {#}}", []Segment{{Code: "{g, select, a {{x}} other {"}, {Text: "#"}, {Code: "}}"}}},
+		{
+			"a {n, choice, 0#none|1<{n} '|' #} b",
+			[]Segment{
+				{Text: "a "},
+				{Code: "{n, choice, 0#"},
+				{Text: "none"},
+				{Code: "|1<{n}"},
+				{Text: " | #"},
+				{Code: "}"},
+				{Text: " b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			msg, err := Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Segments(msg))
+		})
+	}
+}
+
+func TestJoinSegments(t *testing.T) {
+	tests := []struct {
+		segs []Segment
+		want string
+	}{
+		{[]Segment{{Text: "It's {"}, {Code: "{n}"}}, "It's '{'{n}"},
+		{[]Segment{{Text: "It'"}, {Code: "{n}"}}, "It''{n}"},
+		{[]Segment{{Text: "#"}, {Code: "{n, plural, other {"}, {Text: "#{}"}, {Code: "}}"}}, "#{n, plural, other {'#{}'}}"},
+		{[]Segment{{Code: "{g, select, other {"}, {Text: "#"}, {Code: "}}"}}, "{g, select, other {#}}"},
+		{[]Segment{{Code: "{n, choice, 0#{x}|1<y}"}, {Text: "|#"}}, "{n, choice, 0#{x}|1<y}|#"},
+		{[]Segment{{Code: "{n, choice, 0#"}, {Text: "a|b#"}, {Code: "|1≤{n}"}, {Text: "{x}"}, {Code: "}"}, {Text: "|"}}, "{n, choice, 0#a'|'b#|1≤{n}'{x}'}|"},
+		{[]Segment{{Code: "{n, plural, =1 {{g, select, other {"}, {Text: "#"}, {Code: "}}} other {"}, {Text: "#"}, {Code: "}}"}}, "{n, plural, =1 {{g, select, other {#}}} other {'#'}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, JoinSegments(tt.segs))
+		})
+	}
+}
+
+func TestJoinSegments_Corpus(t *testing.T) {
+	for _, in := range corpus {
+		msg, err := Parse(in)
+		if err != nil {
+			continue
+		}
+
+		assert.Equal(t, msg.String(), JoinSegments(Segments(msg)), in)
+	}
+}
+
+// FuzzJoinSegments checks that joined segments print the same message.
+func FuzzJoinSegments(f *testing.F) {
+	for _, in := range corpus {
+		f.Add(in)
+	}
+
+	f.Add("{n, choice, 0#{m, choice, 0#a'|'b|1<{k, plural, other {#}}}|1<'{'}")
+
+	f.Fuzz(func(t *testing.T, in string) {
+		msg, err := Parse(in)
+		if err != nil {
+			t.Skip()
+		}
+
+		assert.Equal(t, msg.String(), JoinSegments(Segments(msg)))
+	})
+}
@@ -0,0 +1,175 @@
+package parse
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Validate checks message syntax and returns all errors found, or nil.
+// Unlike Parse, it does not stop at the first error: a broken expression
+// is reported and skipped, and parsing goes on after its closing brace.
+// It also reports select and plural expressions without the 'other' case,
+// after syntax errors.
+func Validate(msg string) []*SyntaxError {
+	// lenient lexer does not fail, invalid runes are reported by parser
+	tokens, _ := lex(msg, true)
+
+	p := &parser{src: msg, tokens: tokens, failAt: -1, recovering: true, exprAt: map[*Expr]int{}}
+	m := p.message()
+
+	Inspect(m, func(n Node) bool {
+		if e, ok := n.(*Expr); ok {
+			p.checkCases(e)
+		}
+
+		return true
+	})
+
+	return p.errs
+}
+
+// skipBroken reports expression at start that could not be parsed
+// and skips it with nested expressions, up to its closing brace.
+func (p *parser) skipBroken(start int) {
+	switch {
+	case p.failAt < 0:
+		p.errorf(p.tokens[p.skipElided(start)].offset, "", "invalid expression")
+	case p.tokens[p.failAt].typ != tokEOF:
+		err, _ := p.error().(*SyntaxError)
+		err.Suggestion = p.suggestion()
+		p.report(err)
+	}
+
+	// offsets of open braces
+	var open []int
+
+	for i := p.skipElided(start); ; i++ {
+		switch t := p.tokens[i]; t.typ {
+		case tokExprStart, tokSubStart:
+			open = append(open, t.offset)
+		case tokExprEnd, tokSubEnd:
+			open = open[:len(open)-1]
+		case tokEOF:
+			p.pos = i
+			p.unclosed(open[len(open)-1])
+
+			return
+		}
+
+		if len(open) == 0 {
+			p.pos = i + 1
+
+			return
+		}
+	}
+}
+
+// suggestion returns an obvious fix of the deepest failure, if any.
+func (p *parser) suggestion() string {
+	switch {
+	case p.tokens[p.failAt].val == "'":
+		return "apostrophes are not allowed inside braces"
+	case p.expected == `"{" Message "}"`:
+		return "wrap case message in braces"
+	case p.expected == "<limit>":
+		return "start choice case with a number and #, like 0#"
+	default:
+		return ""
+	}
+}
+
+// unclosed reports brace at offset without a pair, and an apostrophe
+// that probably quoted the closing brace. The rest of message is skipped,
+// so only the innermost unclosed brace is reported.
+func (p *parser) unclosed(offset int) {
+	if p.unclosedReported {
+		return
+	}
+
+	p.unclosedReported = true
+
+	// unclosed quoted text runs to the end of message
+	if last := p.tokens[len(p.tokens)-2]; last.typ == tokQuoted && last.offset > offset {
+		if _, _, closed := lexQuoted(p.src[last.offset:]); !closed {
+			p.errorf(last.offset, "use '' for a literal apostrophe", "apostrophe quotes the rest of message")
+		}
+	}
+
+	p.errorf(offset, "add '}' to close it", "unclosed brace")
+}
+
+// checkCases reports select and plural expressions without the 'other' case
+// and cases of functions that have none.
+func (p *parser) checkCases(e *Expr) {
+	if e.Func == "choice" {
+		return
+	}
+
+	funcs := []string{e.Func}
+	for _, s := range e.Selectors {
+		funcs = append(funcs, s.Func)
+	}
+
+	for _, fn := range funcs {
+		if !isSelectFunc(fn) {
+			p.errorf(p.exprAt[e], "use select, plural or selectordinal", "unexpected function %q in expression with cases", fn)
+
+			return
+		}
+	}
+
+	other := strings.TrimSpace(strings.Repeat("other ", len(funcs)))
+	hasOther := slices.ContainsFunc(e.Cases, func(c *Case) bool {
+		return strings.Join(append([]string{c.Name}, c.Keys...), " ") == other
+	})
+
+	if !hasOther {
+		p.errorf(p.exprAt[e], fmt.Sprintf("add %s {...} case", other), "no '%s' case in %s", other, strings.Join(funcs, " "))
+	}
+}
+
+func (p *parser) errorf(offset int, suggestion, format string, args ...any) {
+	p.report(&SyntaxError{
+		Pos:        positionAt(p.src, offset),
+		Msg:        fmt.Sprintf(format, args...),
+		Suggestion: suggestion,
+	})
+}
+
+// report adds error, expressions nested in cases are parsed by every
+// alternative of the outer expression, so their errors are added once.
+func (p *parser) report(err *SyntaxError) {
+	if slices.ContainsFunc(p.errs, func(e *SyntaxError) bool { return *e == *err }) {
+		return
+	}
+
+	p.errs = append(p.errs, err)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWord(c byte) bool {
+	return isDigit(c) || c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// positionAt converts byte offset to position, columns are counted in runes.
+func positionAt(src string, offset int) Position {
+	pos := Position{Offset: offset, Line: 1, Column: 1}
+	for _, r := range src[:offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+
+	return pos
+}
@@ -0,0 +1,155 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"text", "Hello, world!", nil},
+		{"escaped", "It''s '{name}' and '", nil},
+		{"args", "{name} {age, number, integer} {d, date}", nil},
+		{
+			"plural",
+			"{count, plural, offset:1 =0 {none} one {# '#' item} other {# items}}",
+			nil,
+		},
+		{"unclosed arg", "Hello {name", []string{"1:7: unclosed brace; add '}' to close it"}},
+		{
+			"missing other",
+			"{gender, select, male {He} female {She}}",
+			[]string{"1:1: no 'other' case in select; add other {...} case"},
+		},
+		{
+			"stray apostrophe",
+			"{count, plural, one {it'} other {them}}",
+			[]string{
+				"1:24: apostrophe quotes the rest of message; use '' for a literal apostrophe",
+				"1:21: unclosed brace; add '}' to close it",
+			},
+		},
+		{
+			"apostrophe in argument",
+			"{na'me}",
+			[]string{`1:4: unexpected token "'" (expected "}"); apostrophes are not allowed inside braces`},
+		},
+		{
+			"all errors",
+			"{} and {n, plural, one # other {x}} and {g, select, a {b}}",
+			[]string{
+				`1:2: unexpected token "}" (expected <ident>)`,
+				`1:24: unexpected token "#" (expected "{" Message "}"); wrap case message in braces`,
+				"1:41: no 'other' case in select; add other {...} case",
+			},
+		},
+		{
+			"case without name",
+			"{n, plural, {x} other {y}}",
+			[]string{`1:13: unexpected token "{" (expected "}")`},
+		},
+		{"multi-selector", "{g, n, select plural, male one {# him} other other {#}}", nil},
+		{
+			"multi-selector without other",
+			"{g, n, select plural, male one {x} other one {y}}",
+			[]string{"1:1: no 'other other' case in select plural; add other other {...} case"},
+		},
+		{
+			"multi-selector missing key",
+			"{g, n, select plural, male {x} other other {y}}",
+			[]string{`1:28: unexpected token "{" (expected <ident>)`},
+		},
+		{
+			"multi-selector function",
+			"{g, n, select number, other other {y}}",
+			[]string{`1:1: unexpected function "number" in expression with cases; use select, plural or selectordinal`},
+		},
+		{"choice", "{n, choice, 0#none|1#one '|' {n}|1<{n} '{'many'}'}", nil},
+		{
+			"choice limit",
+			"{n, choice, 0#none|x#one|1<many} {m, choice, 0 none}",
+			[]string{
+				`1:20: unexpected token "x" (expected <limit>); start choice case with a number and #, like 0#`,
+				`1:48: unexpected token "n" (expected "#", "<" or "≤")`,
+			},
+		},
+		{
+			"invalid offset",
+			"{n, plural, offset:09 other {x}}",
+			[]string{`1:20: unexpected token "09" (expected <int>)`},
+		},
+		{
+			"negative offset",
+			"{n, plural, offset:-1 other {x}}",
+			[]string{`1:20: unexpected token "-" (expected "{" Message "}"); wrap case message in braces`},
+		},
+		{
+			"nested",
+			"{g, select, a {{n, plural, one {x}}} other {{} {y}}} {z",
+			[]string{
+				`1:46: unexpected token "}" (expected <ident>)`,
+				"1:54: unclosed brace; add '}' to close it",
+				"1:16: no 'other' case in plural; add other {...} case",
+			},
+		},
+		{"unclosed choice", "{n, choice, 0#none|1#one", []string{"1:1: unclosed brace; add '}' to close it"}},
+		{
+			"multiline",
+			"Hi\n{name, number, integer",
+			[]string{"2:1: unclosed brace; add '}' to close it"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range Validate(tt.in) {
+				got = append(got, err.Error())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate_Parse(t *testing.T) {
+	// messages Parse rejects are invalid for Validate too
+	for _, in := range []string{
+		"{",
+		"{name",
+		"{}",
+		"{1}",
+		"{n, plural, one {x}",
+		"{n, plural, one x other {y}}",
+		"{n number}",
+		"{n, number integer}",
+		"{n, select, a {b} other {'}}",
+		"{a, b, select plural, x {y}}",
+		"{a, b, c, select plural, x y z {}}",
+		"{n, choice, 0#a|x#b}",
+		"{n, plural, offset:09 other {x}}",
+		"{n, plural, offset:-1 other {x}}",
+	} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+		assert.NotEmpty(t, Validate(in), in)
+	}
+}
+
+// FuzzValidate checks that Validate rejects what Parse rejects, and never panics.
+func FuzzValidate(f *testing.F) {
+	for _, in := range corpus {
+		f.Add(in)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		errs := Validate(in)
+		if _, err := Parse(in); err != nil {
+			assert.NotEmpty(t, errs, "parse error: %v", err)
+		}
+	})
+}
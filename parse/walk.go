@@ -0,0 +1,145 @@
+package parse
+
+import "fmt"
+
+// Node is any node of the message tree:
+// *Message, *Fragment, *PlainArg, *Func, *Expr or *Case.
+type Node interface {
+	node()
+}
+
+func (*Message) node()  {}
+func (*Fragment) node() {}
+func (*PlainArg) node() {}
+func (*Func) node()     {}
+func (*Expr) node()     {}
+func (*Case) node()     {}
+
+// Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree in depth-first order like ast.Walk:
+// it starts by calling v.Visit(node); node must not be nil.
+func Walk(node Node, v Visitor) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Message:
+		for _, f := range n.Fragments {
+			Walk(f, v)
+		}
+	case *Fragment:
+		switch {
+		case n.PlainArg != nil:
+			Walk(n.PlainArg, v)
+		case n.Func != nil:
+			Walk(n.Func, v)
+		case n.Expr != nil:
+			Walk(n.Expr, v)
+		}
+	case *Expr:
+		for _, c := range n.Cases {
+			Walk(c, v)
+		}
+	case *Case:
+		if n.Message != nil {
+			Walk(n.Message, v)
+		}
+	case *PlainArg, *Func:
+		// leaves
+	default:
+		panic(fmt.Sprintf("parse.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order like ast.Inspect:
+// it starts by calling f(node); node must not be nil. If f returns true,
+// Inspect invokes f recursively for each of the children of node,
+// followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, inspector(f))
+}
+
+// Rewrite replaces nodes of the tree bottom-up with results of f.
+// Children are rewritten before their parent, so f sees already rewritten children.
+// If f returns nil for a fragment or a case, it is removed,
+// a case with nil message gets an empty one.
+// The tree is modified in place, the rewritten root is returned.
+//
+// f must return a node of the same type it got, Rewrite panics otherwise.
+func Rewrite(node Node, f func(Node) Node) Node {
+	switch n := node.(type) {
+	case *Message:
+		fragments := n.Fragments[:0]
+		for _, fr := range n.Fragments {
+			if fr := rewrite(fr, f); fr != nil {
+				fragments = append(fragments, fr)
+			}
+		}
+		n.Fragments = fragments
+	case *Fragment:
+		n.PlainArg = rewrite(n.PlainArg, f)
+		n.Func = rewrite(n.Func, f)
+		n.Expr = rewrite(n.Expr, f)
+	case *Expr:
+		cases := n.Cases[:0]
+		for _, c := range n.Cases {
+			if c := rewrite(c, f); c != nil {
+				cases = append(cases, c)
+			}
+		}
+		n.Cases = cases
+	case *Case:
+		n.Message = rewrite(n.Message, f)
+		if n.Message == nil {
+			n.Message = &Message{}
+		}
+	case *PlainArg, *Func:
+		// leaves
+	default:
+		panic(fmt.Sprintf("parse.Rewrite: unexpected node type %T", n))
+	}
+
+	return f(node)
+}
+
+// rewrite rewrites a typed child, nil children are kept as is.
+func rewrite[T interface {
+	Node
+	comparable
+}](node T, f func(Node) Node) T {
+	var zero T
+	if node == zero {
+		return zero
+	}
+
+	res := Rewrite(node, f)
+	if res == nil {
+		return zero
+	}
+
+	n, ok := res.(T)
+	if !ok {
+		panic(fmt.Sprintf("parse.Rewrite: %T replaced with %T", node, res))
+	}
+
+	return n
+}
@@ -0,0 +1,133 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const walkMsg = "Hi {name}, {gender, select, male {he has {count, number}} other {they}}!"
+
+func TestInspect(t *testing.T) {
+	msg, err := Parse(walkMsg)
+	require.NoError(t, err)
+
+	var nodes []string
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case nil:
+			nodes = append(nodes, "end")
+		case *Message:
+			nodes = append(nodes, "message")
+		case *Fragment:
+			nodes = append(nodes, "fragment")
+		case *PlainArg:
+			nodes = append(nodes, "arg "+n.Name)
+		case *Func:
+			nodes = append(nodes, "func "+n.ArgName)
+		case *Expr:
+			nodes = append(nodes, "expr "+n.Name)
+		case *Case:
+			nodes = append(nodes, "case "+n.Name)
+
+			return false
+		}
+
+		return true
+	})
+
+	assert.Equal(t, []string{
+		"message",
+		"fragment", "end",
+		"fragment", "arg name", "end", "end",
+		"fragment", "end",
+		"fragment", "expr gender", "case male", "case other", "end", "end",
+		"fragment", "end",
+		"end",
+	}, nodes)
+}
+
+type countVisitor map[string]int
+
+func (v countVisitor) Visit(n Node) Visitor {
+	if n != nil {
+		v[fmt.Sprintf("%T", n)]++
+	}
+
+	return v
+}
+
+func TestWalk(t *testing.T) {
+	msg, err := Parse(walkMsg)
+	require.NoError(t, err)
+
+	v := countVisitor{}
+	Walk(msg, v)
+
+	assert.Equal(t, countVisitor{
+		"*parse.Message":  3,
+		"*parse.Fragment": 8,
+		"*parse.PlainArg": 1,
+		"*parse.Func":     1,
+		"*parse.Expr":     1,
+		"*parse.Case":     2,
+	}, v)
+}
+
+func TestRewrite(t *testing.T) {
+	msg, err := Parse(walkMsg)
+	require.NoError(t, err)
+
+	res := Rewrite(msg, func(n Node) Node {
+		switch n := n.(type) {
+		case *Fragment:
+			if n.Text == "!" {
+				return nil
+			}
+			n.Text = strings.ToUpper(n.Text)
+		case *PlainArg:
+			n.Name = "username"
+		case *Func:
+			n.ArgName = "total"
+		case *Case:
+			if n.Name == "male" {
+				return nil
+			}
+		}
+
+		return n
+	})
+	require.Same(t, msg, res)
+
+	var texts, args []string
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case *Fragment:
+			if n.Text != "" {
+				texts = append(texts, n.Text)
+			}
+		case *PlainArg:
+			args = append(args, n.Name)
+		case *Case:
+			args = append(args, "case "+n.Name)
+		}
+
+		return true
+	})
+
+	assert.Equal(t, []string{"HI ", ", ", "THEY"}, texts)
+	assert.Equal(t, []string{"username", "case other"}, args)
+
+	assert.Panics(t, func() {
+		Rewrite(msg, func(n Node) Node {
+			if _, ok := n.(*PlainArg); ok {
+				return &Func{}
+			}
+
+			return n
+		})
+	})
+}